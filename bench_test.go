@@ -0,0 +1,116 @@
+package s_log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardWriter) Close() error                { return nil }
+
+var benchFormatters = []struct {
+	name string
+	f    Formatter
+}{
+	{"JSON", JSON()},
+	{"FastJSON", FastJSON()},
+	{"Text", Text()},
+	{"Logfmt", Logfmt()},
+	{"ColorText", ColorText()},
+	{"ColorJSON", ColorJSON()},
+	{"ECS", ECS()},
+}
+
+var benchWriters = []struct {
+	name string
+	w    func() Writer
+}{
+	{"Discard", func() Writer { return discardWriter{} }},
+	{"Async", func() Writer { return Async(discardWriter{}, 4096) }},
+	{"Multi", func() Writer { return Multi(discardWriter{}, discardWriter{}) }},
+}
+
+func benchLogger(b *testing.B, f Formatter, w Writer, interceptor bool) *Logger {
+	opts := []Option{WithFormatter(f), WithWriter(w)}
+	if interceptor {
+		opts = append(opts, WithInterceptor(func(ctx context.Context, r *Record) *Record {
+			r.Attrs = append(r.Attrs, slog.String("env", "bench"))
+			return r
+		}))
+	}
+	l, err := New(opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = l.Close() })
+	return l
+}
+
+func logRequest(l *slog.Logger) {
+	l.LogAttrs(context.Background(), slog.LevelInfo, "request",
+		slog.String("method", "GET"), slog.String("path", "/v1/users"), slog.Int("status", 200),
+		slog.Duration("elapsed", time.Millisecond), slog.Bool("cached", false))
+}
+
+func BenchmarkFormatters(b *testing.B) {
+	for _, bf := range benchFormatters {
+		for _, bw := range benchWriters {
+			for _, ic := range []bool{false, true} {
+				name := bf.name + "/" + bw.name
+				if ic {
+					name += "/Interceptor"
+				}
+				b.Run(name, func(b *testing.B) {
+					l := benchLogger(b, bf.f, bw.w(), ic).With("svc", "api")
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						logRequest(l)
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkFormattersParallel(b *testing.B) {
+	for _, bf := range benchFormatters {
+		b.Run(bf.name, func(b *testing.B) {
+			l := benchLogger(b, bf.f, discardWriter{}, false).With("svc", "api")
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logRequest(l)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkHandler(b *testing.B) {
+	for _, bf := range benchFormatters {
+		b.Run(bf.name, func(b *testing.B) {
+			logger := slog.New(bf.f.Format(io.Discard, nil)).With("svc", "api")
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logRequest(logger)
+			}
+		})
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	l := benchLogger(b, FastJSON(), discardWriter{}, false)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debug("disabled", "k", 1)
+	}
+}
@@ -0,0 +1,155 @@
+package s_log
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var helpers struct {
+	active atomic.Bool
+	marked sync.Map // pc of the Helper call -> struct{}
+	funcs  sync.Map // function name -> struct{}
+	// frames caches isHelper per pc. It is copied on write so lookups on
+	// the logging path do not allocate; a cache from an older gen is stale.
+	frames   atomic.Pointer[helperFrames]
+	framesMu sync.Mutex
+	gen      atomic.Uint64
+}
+
+type helperFrames struct {
+	gen uint64
+	ok  map[uintptr]bool
+}
+
+// Helper marks the calling function as a logging helper, like
+// testing.T.Helper: records logged from it report the location of its
+// caller instead.
+func Helper() {
+	var pc [1]uintptr
+	runtime.Callers(2, pc[:])
+	if _, ok := helpers.marked.Load(pc[0]); ok {
+		return
+	}
+	if _, loaded := helpers.funcs.LoadOrStore(frameFunc(pc[0]), struct{}{}); !loaded {
+		helpers.gen.Add(1)
+	}
+	helpers.marked.Store(pc[0], struct{}{})
+	helpers.active.Store(true)
+}
+
+func isHelper(pc uintptr) bool {
+	gen := helpers.gen.Load()
+	if f := helpers.frames.Load(); f != nil && f.gen == gen {
+		if ok, found := f.ok[pc]; found {
+			return ok
+		}
+	}
+	_, ok := helpers.funcs.Load(frameFunc(pc))
+	helpers.framesMu.Lock()
+	next := &helperFrames{gen: gen, ok: map[uintptr]bool{pc: ok}}
+	if f := helpers.frames.Load(); f != nil && f.gen == gen {
+		maps.Copy(next.ok, f.ok)
+	}
+	helpers.frames.Store(next)
+	helpers.framesMu.Unlock()
+	return ok
+}
+
+// callerPC walks up the current stack from pc past skip frames and any
+// helper frames. pc is returned unchanged when it is not on the stack.
+func callerPC(pc uintptr, skip int) uintptr {
+	var buf [64]uintptr
+	pcs := buf[:runtime.Callers(2, buf[:])]
+	for i, p := range pcs {
+		if p != pc {
+			continue
+		}
+		for _, p := range pcs[i:] {
+			if helpers.active.Load() && isHelper(p) {
+				continue
+			}
+			if skip == 0 {
+				return p
+			}
+			skip--
+		}
+		break
+	}
+	return pc
+}
+
+// WithCallerSkip returns a logger whose records report the source location
+// n frames above the logging call, for use inside wrapper functions.
+func WithCallerSkip(n int) *slog.Logger {
+	return std.WithCallerSkip(n)
+}
+
+func (l *Logger) WithCallerSkip(n int) *slog.Logger {
+	return withCallerSkip(l.Logger, n)
+}
+
+func withCallerSkip(l *slog.Logger, n int) *slog.Logger {
+	h, ok := l.Handler().(*swapHandler)
+	if !ok || n <= 0 {
+		return l
+	}
+	return slog.New(&swapHandler{src: h.src, ops: h.ops, skip: h.skip + n})
+}
+
+func (l *Logger) logSkip(ctx context.Context, skip int, level slog.Level, msg string, args ...any) {
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(skip+3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// LogSkip logs at level with the source location skip frames above its
+// caller; skip 0 reports the caller itself.
+func (l *Logger) LogSkip(ctx context.Context, skip int, level slog.Level, msg string, args ...any) {
+	l.logSkip(ctx, skip, level, msg, args...)
+}
+
+func (l *Logger) DebugSkip(skip int, msg string, args ...any) {
+	l.logSkip(context.Background(), skip, slog.LevelDebug, msg, args...)
+}
+
+func (l *Logger) InfoSkip(skip int, msg string, args ...any) {
+	l.logSkip(context.Background(), skip, slog.LevelInfo, msg, args...)
+}
+
+func (l *Logger) WarnSkip(skip int, msg string, args ...any) {
+	l.logSkip(context.Background(), skip, slog.LevelWarn, msg, args...)
+}
+
+func (l *Logger) ErrorSkip(skip int, msg string, args ...any) {
+	l.logSkip(context.Background(), skip, slog.LevelError, msg, args...)
+}
+
+func LogSkip(ctx context.Context, skip int, level slog.Level, msg string, args ...any) {
+	std.logSkip(ctx, skip, level, msg, args...)
+}
+
+func DebugSkip(skip int, msg string, args ...any) {
+	std.logSkip(context.Background(), skip, slog.LevelDebug, msg, args...)
+}
+
+func InfoSkip(skip int, msg string, args ...any) {
+	std.logSkip(context.Background(), skip, slog.LevelInfo, msg, args...)
+}
+
+func WarnSkip(skip int, msg string, args ...any) {
+	std.logSkip(context.Background(), skip, slog.LevelWarn, msg, args...)
+}
+
+func ErrorSkip(skip int, msg string, args ...any) {
+	std.logSkip(context.Background(), skip, slog.LevelError, msg, args...)
+}
@@ -0,0 +1,109 @@
+package s_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func sourceLogger(t *testing.T, opts ...Option) (*Logger, *bytes.Buffer) {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	l, err := New(append([]Option{WithWriter(&testWriter{buf: buf}), WithFormatter(JSON()), WithAddSource(true)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, buf
+}
+
+func sourceLine(t *testing.T, buf *bytes.Buffer) int {
+	t.Helper()
+	var entry struct {
+		Source struct {
+			Line int `json:"line"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid record %q: %v", buf.String(), err)
+	}
+	buf.Reset()
+	return entry.Source.Line
+}
+
+func line() int {
+	_, _, n, _ := runtime.Caller(1)
+	return n
+}
+
+func logViaHelper(l *Logger, msg string) {
+	Helper()
+	l.Info(msg)
+}
+
+func logViaNestedHelper(l *Logger, msg string) {
+	Helper()
+	logViaHelper(l, msg)
+}
+
+func logViaSkip(l *Logger, msg string) {
+	l.InfoSkip(1, msg)
+}
+
+func logViaCallerSkip(l *Logger, msg string) {
+	l.WithCallerSkip(1).Info(msg)
+}
+
+func TestCallerReporting(t *testing.T) {
+	l, buf := sourceLogger(t)
+
+	logViaHelper(l, "helper")
+	if want := line() - 1; sourceLine(t, buf) != want {
+		t.Errorf("Helper should report the caller line %d", want)
+	}
+	logViaNestedHelper(l, "nested")
+	if want := line() - 1; sourceLine(t, buf) != want {
+		t.Errorf("nested helpers should report the caller line %d", want)
+	}
+	logViaSkip(l, "skip")
+	if want := line() - 1; sourceLine(t, buf) != want {
+		t.Errorf("InfoSkip should report the caller line %d", want)
+	}
+	logViaCallerSkip(l, "callerskip")
+	if want := line() - 1; sourceLine(t, buf) != want {
+		t.Errorf("WithCallerSkip should report the caller line %d", want)
+	}
+	l.InfoSkip(0, "direct")
+	if want := line() - 1; sourceLine(t, buf) != want {
+		t.Errorf("InfoSkip(0) should report its own call line %d", want)
+	}
+	l.WithCallerSkip(1).With("k", "v").Info("derived")
+	if got := sourceLine(t, buf); got == 0 {
+		t.Error("derived logger should keep a source location")
+	}
+}
+
+func TestCallerReporting_Interceptor(t *testing.T) {
+	l, buf := sourceLogger(t, WithInterceptor(func(ctx context.Context, r *Record) *Record { return r }))
+	l.Info("intercepted")
+	if want := line() - 1; sourceLine(t, buf) != want {
+		t.Errorf("interceptor should keep the source line %d", want)
+	}
+}
+
+func TestHelper_NoAllocsForOtherCallers(t *testing.T) {
+	l, buf := sourceLogger(t)
+	logViaHelper(l, "activate")
+	buf.Reset()
+
+	fast, err := New(WithWriter(discardWriter{}), WithFormatter(FastJSON()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testing.AllocsPerRun(100, func() {
+		fast.Info("request", "method", "GET", "status", 200)
+	}); n != 0 {
+		t.Errorf("records outside helpers should not walk the stack, got %v allocs", n)
+	}
+}
@@ -0,0 +1,59 @@
+package s_log
+
+import (
+	"io"
+	"os"
+)
+
+type ColorMode int
+
+const (
+	ColorAlways ColorMode = iota
+	ColorAuto
+	ColorNever
+)
+
+// WithColor controls whether color formatters emit ANSI codes. With
+// ColorAuto, NO_COLOR disables color, FORCE_COLOR enables it, and otherwise
+// color is used only when TERM is not "dumb" and the writer is a terminal.
+func WithColor(mode ColorMode) FormatOption {
+	return func(o *formatOptions) { o.color = mode }
+}
+
+func (o *formatOptions) colorEnabled(w io.Writer) bool {
+	switch o.color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if v := os.Getenv("FORCE_COLOR"); v != "" && v != "0" && v != "false" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+type fileBacked interface {
+	file() *os.File
+}
+
+func isTerminal(w io.Writer) bool {
+	var f *os.File
+	switch x := w.(type) {
+	case *os.File:
+		f = x
+	case fileBacked:
+		f = x.file()
+	}
+	if f == nil {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
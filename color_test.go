@@ -0,0 +1,79 @@
+package s_log
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    ColorMode
+		env     map[string]string
+		enabled bool
+	}{
+		{"always", ColorAlways, map[string]string{"NO_COLOR": "1"}, true},
+		{"never", ColorNever, map[string]string{"FORCE_COLOR": "1"}, false},
+		{"auto not a terminal", ColorAuto, nil, false},
+		{"auto force", ColorAuto, map[string]string{"FORCE_COLOR": "1"}, true},
+		{"auto force disabled", ColorAuto, map[string]string{"FORCE_COLOR": "0"}, false},
+		{"no color wins", ColorAuto, map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false},
+		{"dumb terminal", ColorAuto, map[string]string{"TERM": "dumb"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"NO_COLOR", "FORCE_COLOR", "TERM"} {
+				t.Setenv(k, tt.env[k])
+			}
+			o := newFormatOptions([]FormatOption{WithColor(tt.mode)})
+			if got := o.colorEnabled(&bytes.Buffer{}); got != tt.enabled {
+				t.Errorf("colorEnabled() = %v, want %v", got, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) || isTerminal(&bytes.Buffer{}) || isTerminal(Async(&testWriter{buf: &bytes.Buffer{}}, 1)) {
+		t.Error("files and buffers are not terminals")
+	}
+	if _, ok := Async(Stdout(), 1).(fileBacked); !ok {
+		t.Error("Async should expose the wrapped file")
+	}
+}
+
+func TestAuto(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	if Auto() != Auto() || Auto(WithUTC()) == Auto() {
+		t.Error("Auto() should return a singleton only without options")
+	}
+
+	buf := &bytes.Buffer{}
+	slog.New(Auto().Format(buf, nil)).Info("plain", "k", "v")
+	if strings.Contains(buf.String(), "\x1b[") || !strings.Contains(buf.String(), "level=INFO plain k=v") {
+		t.Errorf("Auto should not color non-terminal output: %q", buf.String())
+	}
+
+	buf.Reset()
+	slog.New(ColorJSON(WithColor(ColorNever)).Format(buf, nil)).Info("plain")
+	if strings.Contains(buf.String(), "\x1b[") || !strings.Contains(buf.String(), `"msg":"plain"`) {
+		t.Errorf("ColorJSON without color should be plain JSON: %q", buf.String())
+	}
+
+	t.Setenv("FORCE_COLOR", "1")
+	buf.Reset()
+	slog.New(Auto().Format(buf, nil)).Info("forced")
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("FORCE_COLOR should enable color: %q", buf.String())
+	}
+}
@@ -0,0 +1,178 @@
+package s_log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+type colorJSONFormatter struct {
+	fo formatOptions
+}
+
+func (f *colorJSONFormatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	h := &fastJSONHandler{w: w, mu: &sync.Mutex{}, fo: &f.fo}
+	if opts != nil {
+		h.opts = *opts
+	}
+	theme := f.fo.activeTheme(w)
+	if theme == nil && f.fo.indent == "" {
+		return h
+	}
+	return &colorJSONHandler{h, theme}
+}
+
+// colorJSONHandler encodes records as plain JSON and then colors the
+// output token by token, so the text stays valid JSON once the escape
+// sequences are stripped.
+type colorJSONHandler struct {
+	*fastJSONHandler
+	theme *Theme
+}
+
+func (h *colorJSONHandler) Handle(ctx context.Context, r slog.Record) error {
+	raw, out := getBuf(), getBuf()
+	*raw = h.encode(*raw, r)
+	*out = append(colorizeJSON(*out, *raw, h.fo.indent, h.theme, r.Level), '\n')
+	h.mu.Lock()
+	_, err := h.w.Write(*out)
+	h.mu.Unlock()
+	putBuf(raw)
+	putBuf(out)
+	return err
+}
+
+func (h *colorJSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &colorJSONHandler{h.fastJSONHandler.WithAttrs(attrs).(*fastJSONHandler), h.theme}
+}
+
+func (h *colorJSONHandler) WithGroup(name string) slog.Handler {
+	return &colorJSONHandler{h.fastJSONHandler.WithGroup(name).(*fastJSONHandler), h.theme}
+}
+
+func colorizeJSON(dst, src []byte, indent string, theme *Theme, level slog.Level) []byte {
+	var stackArr [16]byte
+	stack := stackArr[:0]
+	expectKey := false
+	var key []byte
+	paint := func(tok []byte, color string) {
+		if color == "" {
+			dst = append(dst, tok...)
+			return
+		}
+		dst = append(append(append(dst, color...), tok...), reset...)
+	}
+	valueColor := func(kind slog.Kind) string {
+		if theme == nil {
+			return ""
+		}
+		if c, ok := theme.Keys[string(key)]; ok {
+			return c
+		}
+		return theme.Values[kind]
+	}
+	newline := func() {
+		if indent == "" {
+			return
+		}
+		dst = append(dst, '\n')
+		for range stack {
+			dst = append(dst, indent...)
+		}
+	}
+	for i := 0; i < len(src); {
+		switch c := src[i]; c {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '{', '[':
+			stack = append(stack, c)
+			expectKey = c == '{'
+			dst = append(dst, c)
+			i++
+			if i < len(src) && (src[i] == '}' || src[i] == ']') {
+				stack = stack[:len(stack)-1]
+				dst = append(dst, src[i])
+				i++
+				expectKey = false
+				continue
+			}
+			newline()
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			newline()
+			dst = append(dst, c)
+			expectKey = false
+			i++
+		case ',':
+			dst = append(dst, ',')
+			newline()
+			expectKey = len(stack) > 0 && stack[len(stack)-1] == '{'
+			i++
+		case ':':
+			dst = append(dst, ':')
+			if indent != "" {
+				dst = append(dst, ' ')
+			}
+			i++
+		case '"':
+			end := jsonStringEnd(src, i)
+			tok := src[i:end]
+			top := len(stack) == 1
+			switch {
+			case expectKey:
+				key = tok[1 : len(tok)-1]
+				paint(tok, theme.key())
+				expectKey = false
+			case theme == nil:
+				dst = append(dst, tok...)
+			case top && string(key) == slog.LevelKey:
+				dst = append(append(theme.appendLevel(dst, level), tok...), reset...)
+			case top && string(key) == slog.MessageKey:
+				paint(tok, theme.Message)
+			case top && string(key) == slog.TimeKey:
+				paint(tok, theme.Timestamp)
+			default:
+				paint(tok, valueColor(slog.KindString))
+			}
+			i = end
+		default:
+			end := i
+			for end < len(src) && !isJSONDelim(src[end]) {
+				end++
+			}
+			kind := slog.KindFloat64
+			switch src[i] {
+			case 't', 'f':
+				kind = slog.KindBool
+			case 'n':
+				kind = slog.KindAny
+			}
+			paint(src[i:end], valueColor(kind))
+			i = end
+		}
+	}
+	return dst
+}
+
+func jsonStringEnd(src []byte, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+func isJSONDelim(c byte) bool {
+	switch c {
+	case ',', ':', '{', '}', '[', ']', ' ', '\t', '\n', '\r', '"':
+		return true
+	}
+	return false
+}
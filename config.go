@@ -0,0 +1,222 @@
+package s_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	EnvLevel     = "LOG_LEVEL"
+	EnvFormat    = "LOG_FORMAT"
+	EnvFile      = "LOG_FILE"
+	EnvRotateMB  = "LOG_ROTATE_MB"
+	EnvAddSource = "LOG_ADD_SOURCE"
+	EnvVModule   = "LOG_VMODULE"
+)
+
+type Config struct {
+	Level     string       `json:"level,omitempty" yaml:"level,omitempty"`
+	Format    string       `json:"format,omitempty" yaml:"format,omitempty"`
+	AddSource bool         `json:"add_source,omitempty" yaml:"add_source,omitempty"`
+	File      string       `json:"file,omitempty" yaml:"file,omitempty"`
+	RotateMB  int          `json:"rotate_mb,omitempty" yaml:"rotate_mb,omitempty"`
+	Loggers   string       `json:"loggers,omitempty" yaml:"loggers,omitempty"`
+	VModule   string       `json:"vmodule,omitempty" yaml:"vmodule,omitempty"`
+	Limits    *Limits      `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Sinks     []SinkConfig `json:"sinks,omitempty" yaml:"sinks,omitempty"`
+}
+
+type SinkConfig struct {
+	Type       string  `json:"type" yaml:"type"`
+	Path       string  `json:"path,omitempty" yaml:"path,omitempty"`
+	MaxSizeMB  int     `json:"max_size_mb,omitempty" yaml:"max_size_mb,omitempty"`
+	MaxBackups int     `json:"max_backups,omitempty" yaml:"max_backups,omitempty"`
+	MaxAgeDays int     `json:"max_age_days,omitempty" yaml:"max_age_days,omitempty"`
+	Compress   *bool   `json:"compress,omitempty" yaml:"compress,omitempty"`
+	Async      int     `json:"async,omitempty" yaml:"async,omitempty"`
+	Limits     *Limits `json:"limits,omitempty" yaml:"limits,omitempty"`
+}
+
+func (c Config) Options() []Option {
+	var opts []Option
+	if c.Level != "" {
+		opts = append(opts, WithLevel(c.Level))
+	}
+	if c.Format != "" {
+		if f, ok := formatterByName(c.Format); ok {
+			opts = append(opts, WithFormatter(f))
+		} else {
+			opts = append(opts, errOption(fmt.Errorf("s_log: unknown format %q", c.Format)))
+		}
+	}
+	if c.AddSource {
+		opts = append(opts, WithAddSource(true))
+	}
+	if c.Loggers != "" {
+		opts = append(opts, WithLoggerLevels(c.Loggers))
+	}
+	if c.VModule != "" {
+		opts = append(opts, WithVModule(c.VModule))
+	}
+	if c.Limits != nil {
+		opts = append(opts, WithLimits(*c.Limits))
+	}
+
+	sinks := c.Sinks
+	if c.File != "" {
+		sinks = append(sinks, SinkConfig{Type: "file", Path: c.File, MaxSizeMB: c.RotateMB})
+	}
+	if len(sinks) > 0 {
+		opts = append(opts, withSinks(sinks))
+	}
+	return opts
+}
+
+// withSinks defers creating the sink writers until the configuration is
+// validated. Validation only checks that files can be written, so a rejected
+// configuration leaves no files, directories or goroutines behind.
+func withSinks(sinks []SinkConfig) Option {
+	return func(c *config) { c.sinks = sinks }
+}
+
+func (c *config) sinksWriter() Writer {
+	if len(c.sinks) == 1 {
+		return c.sinks[0].writer()
+	}
+	writers := make([]Writer, len(c.sinks))
+	for i, sc := range c.sinks {
+		writers[i] = sc.writer()
+	}
+	return Multi(writers...)
+}
+
+func (sc SinkConfig) validate() error {
+	var errs []error
+	switch strings.ToLower(sc.Type) {
+	case "", "stdout", "stderr":
+	case "file":
+		if sc.Path == "" {
+			errs = append(errs, errors.New("file sink requires path"))
+		} else if err := sc.baseWriter().(validator).validate(); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown sink type %q", sc.Type))
+	}
+	if sc.Async < 0 {
+		errs = append(errs, fmt.Errorf("invalid async buffer size %d", sc.Async))
+	}
+	if sc.Limits != nil {
+		errs = append(errs, sc.Limits.validate())
+	}
+	return errors.Join(errs...)
+}
+
+func (sc SinkConfig) baseWriter() Writer {
+	switch strings.ToLower(sc.Type) {
+	case "stderr":
+		return Stderr()
+	case "file":
+		fo := []FileOption{WithRotation(orDefault(sc.MaxSizeMB, 100), orDefault(sc.MaxBackups, 7))}
+		if sc.MaxAgeDays != 0 {
+			fo = append(fo, WithMaxAge(sc.MaxAgeDays))
+		}
+		if sc.Compress != nil {
+			fo = append(fo, WithCompress(*sc.Compress))
+		}
+		return File(sc.Path, fo...)
+	}
+	return Stdout()
+}
+
+func (sc SinkConfig) writer() Writer {
+	w := sc.baseWriter()
+	if sc.Async > 0 {
+		w = Async(w, sc.Async)
+	}
+	if sc.Limits != nil {
+		w = Limit(w, *sc.Limits)
+	}
+	return w
+}
+
+func orDefault(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+func ConfigFromEnv() (Config, error) {
+	c := Config{
+		Level:   os.Getenv(EnvLevel),
+		Format:  os.Getenv(EnvFormat),
+		File:    os.Getenv(EnvFile),
+		Loggers: os.Getenv(EnvLoggerLevels),
+		VModule: os.Getenv(EnvVModule),
+	}
+	var errs []error
+	if v := os.Getenv(EnvRotateMB); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("s_log: %s: invalid integer %q", EnvRotateMB, v))
+		}
+		c.RotateMB = n
+	}
+	if v := os.Getenv(EnvAddSource); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("s_log: %s: invalid boolean %q", EnvAddSource, v))
+		}
+		c.AddSource = b
+	}
+	return c, errors.Join(errs...)
+}
+
+func FromEnv() []Option {
+	c, err := ConfigFromEnv()
+	opts := c.Options()
+	if err != nil {
+		opts = append(opts, errOption(err))
+	}
+	return opts
+}
+
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Level, "log-level", c.Level, "log level (TRACE/DEBUG/INFO/WARN/ERROR)")
+	fs.StringVar(&c.Format, "log-format", c.Format, "log format (json/text/color/colorjson/auto/console/logfmt/fastjson/ecs/gcp/datadog)")
+	fs.StringVar(&c.File, "log-file", c.File, "log file path, empty for stdout")
+	fs.IntVar(&c.RotateMB, "log-rotate-mb", c.RotateMB, "rotate log file after this many megabytes")
+	fs.BoolVar(&c.AddSource, "log-add-source", c.AddSource, "include source location")
+	fs.StringVar(&c.Loggers, "log-levels", c.Loggers, "per-logger levels, e.g. db=DEBUG,http=WARN")
+	fs.StringVar(&c.VModule, "log-vmodule", c.VModule, "per-file levels, e.g. internal/cache/*=DEBUG")
+}
+
+func ParseConfig(data []byte) (Config, error) {
+	var c Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("s_log: invalid config: %w", err)
+	}
+	return c, nil
+}
+
+func LoadConfig(path string) (Config, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return Config{}, fmt.Errorf("s_log: %s: YAML is not supported directly, unmarshal into s_log.Config with a YAML library", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("s_log: %w", err)
+	}
+	return ParseConfig(data)
+}
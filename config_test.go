@@ -0,0 +1,173 @@
+package s_log
+
+import (
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	file := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv(EnvLevel, "warning")
+	t.Setenv(EnvFormat, "json")
+	t.Setenv(EnvFile, file)
+	t.Setenv(EnvRotateMB, "10")
+	t.Setenv(EnvAddSource, "true")
+
+	if err := Init(FromEnv()...); err != nil {
+		t.Fatalf("Init(FromEnv()) failed: %v", err)
+	}
+	if levelVar.Level() != slog.LevelWarn {
+		t.Errorf("expected WARN, got %v", levelVar.Level())
+	}
+	slog.Warn("env message")
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if !strings.Contains(string(content), `"msg":"env message"`) || !strings.Contains(string(content), `"source"`) {
+		t.Errorf("file should contain JSON record with source: %s", content)
+	}
+}
+
+func TestFromEnv_Invalid(t *testing.T) {
+	t.Setenv(EnvRotateMB, "big")
+	t.Setenv(EnvAddSource, "maybe")
+	t.Setenv(EnvFormat, "xml")
+
+	err := Init(FromEnv()...)
+	if err == nil {
+		t.Fatal("Init() should fail on invalid env")
+	}
+	for _, want := range []string{EnvRotateMB, EnvAddSource, `"xml"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s: %v", want, err)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	dir := t.TempDir()
+	data := `{
+		"level": "debug",
+		"format": "text",
+		"loggers": "db=ERROR",
+		"sinks": [
+			{"type": "file", "path": "` + filepath.ToSlash(filepath.Join(dir, "a.log")) + `", "max_size_mb": 5},
+			{"type": "file", "path": "` + filepath.ToSlash(filepath.Join(dir, "b.log")) + `", "async": 10}
+		]
+	}`
+	c, err := ParseConfig([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseConfig() failed: %v", err)
+	}
+	if len(c.Sinks) != 2 || c.Sinks[0].MaxSizeMB != 5 {
+		t.Errorf("unexpected sinks: %+v", c.Sinks)
+	}
+	if err := Init(c.Options()...); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	defer func() { _ = SetLoggerLevels("") }()
+	slog.Debug("sink message")
+	_ = Close()
+
+	for _, name := range []string{"a.log", "b.log"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if !strings.Contains(string(content), "sink message") {
+			t.Errorf("%s should contain record: %s", name, content)
+		}
+	}
+
+	if _, err := ParseConfig([]byte(`{"levle":"INFO"}`)); err == nil {
+		t.Error("unknown fields should be rejected")
+	}
+	c, _ = ParseConfig([]byte(`{"sinks":[{"type":"syslog"}]}`))
+	if err := Init(c.Options()...); err == nil || !strings.Contains(err.Error(), "syslog") {
+		t.Errorf("unknown sink type should be rejected: %v", err)
+	}
+}
+
+func TestInit_RejectedConfigCreatesNoSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	c := Config{Level: "LOUD", Sinks: []SinkConfig{{Type: "file", Path: path, Async: 16}}}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		if err := Init(c.Options()...); err == nil {
+			t.Fatal("unknown level should be rejected")
+		}
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("rejected configs should not start async writers: %d goroutines before, %d after", before, n)
+	}
+
+	dir := filepath.Join(t.TempDir(), "sub")
+	c = Config{Level: "LOUD", File: filepath.Join(dir, "app.log")}
+	if err := Init(c.Options()...); err == nil {
+		t.Fatal("unknown level should be rejected")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("rejected configs should not create directories: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(dir))
+	if len(entries) != 0 {
+		t.Errorf("rejected configs should leave no files behind, got %v", entries)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	if err := os.WriteFile(path, []byte(`{"level":"ERROR","format":"color"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if c.Level != "ERROR" || c.Format != "color" {
+		t.Errorf("unexpected config: %+v", c)
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "log.yaml")); err == nil {
+		t.Error("YAML files should be rejected")
+	}
+}
+
+func TestConfig_RegisterFlags(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	t.Setenv(EnvLevel, "ERROR")
+	c, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-log-format", "text", "-log-add-source"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if c.Level != "ERROR" || c.Format != "text" || !c.AddSource {
+		t.Errorf("unexpected config: %+v", c)
+	}
+
+	if err := fs.Parse([]string{"-log-level", "debug"}); err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	if err := Init(c.Options()...); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if levelVar.Level() != slog.LevelDebug {
+		t.Errorf("flag should override env, got %v", levelVar.Level())
+	}
+}
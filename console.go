@@ -0,0 +1,138 @@
+package s_log
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	consoleTimeFormat = "15:04:05.000"
+	consoleMsgWidth   = 40
+	faint             = "\x1b[2m"
+)
+
+// Console is a ColorText variant laid out for reading in a terminal:
+//
+//	15:04:05.000 INF request handled                          status=200 main.go:42
+//
+// Multi-line values such as stack traces or SQL are printed indented on the
+// lines that follow.
+func Console(opts ...FormatOption) Formatter {
+	if len(opts) == 0 {
+		return consoleFmt
+	}
+	return &colorFormatter{fo: newFormatOptions(append([]FormatOption{func(o *formatOptions) { o.console = true }}, opts...))}
+}
+
+var levelAbbrevs = map[slog.Level]string{
+	LevelTrace:      "TRC",
+	slog.LevelDebug: "DBG",
+	slog.LevelInfo:  "INF",
+	LevelNotice:     "NTC",
+	slog.LevelWarn:  "WRN",
+	slog.LevelError: "ERR",
+	LevelFatal:      "FTL",
+	LevelPanic:      "PNC",
+}
+
+func levelAbbrev(lv slog.Level) string {
+	if s, ok := levelAbbrevs[lv]; ok {
+		return s
+	}
+	name := levelName(lv)
+	if len(name) > 3 {
+		name = name[:3]
+	}
+	return fmt.Sprintf("%-3s", name)
+}
+
+func isStack(v slog.Value) bool {
+	if v.Kind() != slog.KindAny {
+		return false
+	}
+	s, ok := v.Any().(Stack)
+	return ok && len(s) > 0
+}
+
+func isMultiline(v slog.Value) bool {
+	switch v.Kind() {
+	case slog.KindString:
+		return strings.Contains(v.String(), "\n")
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case Stack:
+			return len(x) > 0
+		case error:
+			return strings.Contains(x.Error(), "\n")
+		}
+	}
+	return false
+}
+
+func (h *colorTextHandler) appendConsole(buf *[]byte, r slog.Record) {
+	if !r.Time.IsZero() && !h.fo.omitTime {
+		tc := h.theme.timestamp()
+		*buf = append(*buf, tc...)
+		if h.fo.timeLayout == "" {
+			t := r.Time
+			if h.fo.timeLoc != nil {
+				t = t.In(h.fo.timeLoc)
+			}
+			*buf = t.AppendFormat(*buf, consoleTimeFormat)
+		} else {
+			*buf = h.appendTime(*buf, r.Time)
+		}
+		if tc != "" {
+			*buf = append(*buf, reset...)
+		}
+		*buf = append(*buf, ' ')
+	}
+	h.appendLevel(buf, r.Level, levelAbbrev(r.Level))
+	*buf = append(*buf, ' ')
+	msg := messageText(r.Message)
+	h.writeColored(buf, h.theme.message(), msg)
+
+	src := h.source(r)
+	if len(h.attrs) > 0 || r.NumAttrs() > 0 || src != "" {
+		for pad := consoleMsgWidth - utf8.RuneCountInString(msg); pad > 0; pad-- {
+			*buf = append(*buf, ' ')
+		}
+	}
+	var deferred []boundAttr
+	h.appendAttrs(buf, r, h.c(faint), &deferred)
+	if src != "" {
+		*buf = append(*buf, ' ')
+		h.writeColored(buf, h.theme.source(), src)
+	}
+	*buf = append(*buf, '\n')
+	h.appendDeferred(buf, deferred)
+}
+
+// appendDeferred writes multi-line values on the lines after the record,
+// indented under their key.
+func (h *colorTextHandler) appendDeferred(buf *[]byte, deferred []boundAttr) {
+	for _, d := range deferred {
+		*buf = append(*buf, "    "...)
+		h.writeColored(buf, h.c(faint), d.prefix+d.a.Key+":")
+		*buf = append(*buf, '\n')
+		text := d.a.Value.String()
+		if err, ok := d.a.Value.Any().(error); ok {
+			text = err.Error()
+		}
+		color := h.valueColor(d.a.Key, d.a.Value)
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			*buf = append(*buf, "        "...)
+			h.writeColored(buf, color, line)
+			*buf = append(*buf, '\n')
+		}
+	}
+}
+
+func (h *colorTextHandler) c(color string) string {
+	if h.theme == nil {
+		return ""
+	}
+	return color
+}
@@ -0,0 +1,66 @@
+package s_log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestConsole(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := Console(WithColor(ColorNever)).Format(buf, &slog.HandlerOptions{AddSource: true})
+	logger := slog.New(h).With("svc", "api").WithGroup("req")
+	logger.Warn("slow query", "ms", 120, "sql", "SELECT *\nFROM users\n", "err", errors.New("line one\nline two"))
+	slog.New(Console(WithColor(ColorNever)).Format(buf, nil)).Info("bare")
+
+	lines := strings.Split(buf.String(), "\n")
+	first := regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d{3} WRN slow query {30} svc=api req\.ms=120 console_test\.go:\d+$`)
+	if !first.MatchString(lines[0]) {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	want := []string{"    req.sql:", "        SELECT *", "        FROM users", "    req.err:", "        line one", "        line two"}
+	for i, w := range want {
+		if lines[1+i] != w {
+			t.Errorf("line %d = %q, want %q", i+1, lines[1+i], w)
+		}
+	}
+	if !regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d{3} INF bare$`).MatchString(lines[7]) {
+		t.Errorf("message without attrs should not be padded: %q", lines[7])
+	}
+}
+
+func TestConsole_Colors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slog.New(Console(WithoutTime()).Format(buf, nil)).Error("boom", "k", "v")
+	output := buf.String()
+	if !strings.HasPrefix(output, bold+fgRed+"ERR"+reset) || !strings.Contains(output, faint+"k"+reset+"=") {
+		t.Errorf("unexpected colored output: %q", output)
+	}
+	if Console() != Console() || Console(WithUTC()) == Console() {
+		t.Error("Console() should return a singleton only without options")
+	}
+}
+
+func TestLevelAbbrev(t *testing.T) {
+	tests := map[slog.Level]string{
+		slog.LevelDebug: "DBG", slog.LevelInfo: "INF", slog.LevelWarn: "WRN", slog.LevelError: "ERR",
+		LevelTrace: "TRC", LevelFatal: "FTL", slog.LevelInfo + 1: "INF",
+	}
+	for lv, want := range tests {
+		if got := levelAbbrev(lv); got != want {
+			t.Errorf("levelAbbrev(%v) = %q, want %q", lv, got, want)
+		}
+	}
+}
+
+func TestColorText_WithAttrsAndGroups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(ColorText(WithColor(ColorNever), WithoutTime()).Format(buf, nil))
+	logger.With("svc", "api").WithGroup("req").Info("m", "id", 7, slog.Group("user", "name", "bob"))
+	if got := buf.String(); got != "level=INFO m svc=api req.id=7 req.user.name=bob\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
@@ -0,0 +1,143 @@
+package s_log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+type DedupMode int
+
+const (
+	DedupNone DedupMode = iota
+	// DedupKeepLast keeps the value added last, at the position of the first.
+	DedupKeepLast
+	DedupKeepFirst
+	// DedupSuffix keeps every value, renaming repeats to key#1, key#2, ...
+	DedupSuffix
+)
+
+// WithDedup removes repeated attribute keys within each object of a record,
+// across attrs bound with With, groups and the record's own attrs. Groups
+// with the same key are merged.
+func WithDedup(mode DedupMode) Option {
+	return func(c *config) { c.dedup = mode }
+}
+
+// dedupHandler keeps bound attrs itself, nested under their groups, so the
+// whole record can be deduplicated before the formatter sees it.
+type dedupHandler struct {
+	slog.Handler
+	mode   DedupMode
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(h.mode.dedupTop(append(slices.Clip(h.attrs), nestAttrs(h.groups, attrs)...))...)
+	return h.Handler.Handle(ctx, nr)
+}
+
+func nestAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+// builtinAttrs holds the keys the formatters write themselves. Top-level
+// attrs cannot replace them, so they are dropped or, with DedupSuffix,
+// renamed.
+var builtinAttrs = []slog.Attr{{Key: slog.TimeKey}, {Key: slog.LevelKey}, {Key: slog.MessageKey}, {Key: slog.SourceKey}}
+
+func (m DedupMode) dedupTop(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(builtinAttrs)+len(attrs))
+	out = m.add(append(out, builtinAttrs...), attrs, len(builtinAttrs))
+	return m.dedupGroups(out[len(builtinAttrs):])
+}
+
+func (m DedupMode) dedup(attrs []slog.Attr) []slog.Attr {
+	return m.dedupGroups(m.add(make([]slog.Attr, 0, len(attrs)), attrs, 0))
+}
+
+func (m DedupMode) dedupGroups(out []slog.Attr) []slog.Attr {
+	for i, a := range out {
+		if a.Value.Kind() == slog.KindGroup {
+			out[i].Value = slog.GroupValue(m.dedup(a.Value.Group())...)
+		}
+	}
+	return slices.DeleteFunc(out, func(a slog.Attr) bool {
+		return a.Value.Kind() == slog.KindGroup && len(a.Value.Group()) == 0
+	})
+}
+
+// add appends attrs to out, whose first reserved entries are built-in keys.
+func (m DedupMode) add(out, attrs []slog.Attr, reserved int) []slog.Attr {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		isGroup := a.Value.Kind() == slog.KindGroup
+		if isGroup && a.Key == "" {
+			out = m.add(out, a.Value.Group(), reserved)
+			continue
+		}
+		i := slices.IndexFunc(out, func(b slog.Attr) bool { return b.Key == a.Key })
+		switch {
+		case i < 0:
+			out = append(out, a)
+		case i < reserved:
+			if m == DedupSuffix {
+				a.Key = uniqueKey(out, a.Key)
+				out = append(out, a)
+			}
+		case isGroup && out[i].Value.Kind() == slog.KindGroup:
+			merged := append(slices.Clip(out[i].Value.Group()), a.Value.Group()...)
+			out[i].Value = slog.GroupValue(merged...)
+		case m == DedupKeepLast:
+			out[i] = a
+		case m == DedupSuffix:
+			a.Key = uniqueKey(out, a.Key)
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func uniqueKey(attrs []slog.Attr, key string) string {
+	for n := 1; ; n++ {
+		k := key + "#" + strconv.Itoa(n)
+		if !slices.ContainsFunc(attrs, func(a slog.Attr) bool { return a.Key == k }) {
+			return k
+		}
+	}
+}
+
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = append(slices.Clip(h.attrs), nestAttrs(h.groups, attrs)...)
+	return &h2
+}
+
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	return &h2
+}
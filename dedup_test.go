@@ -0,0 +1,69 @@
+package s_log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWithDedup(t *testing.T) {
+	tests := []struct {
+		mode DedupMode
+		want string
+	}{
+		{DedupKeepLast, `"request_id":"b","user":"u","http":{"path":"/x","method":"POST","status":200}}`},
+		{DedupKeepFirst, `"request_id":"a","user":"u","http":{"path":"/x","method":"GET","status":200}}`},
+		{DedupSuffix, `"request_id":"a","user":"u","request_id#1":"b","http":{"path":"/x","method":"GET","status":200,"method#1":"POST"}}`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		l, err := New(WithWriter(&testWriter{buf: buf}), WithFormatter(JSON(WithoutTime())), WithDedup(tt.mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.With("request_id", "a", "user", "u").With("request_id", "b").
+			WithGroup("http").With("path", "/x", "method", "GET").
+			Info("done", "status", 200, "method", "POST")
+		if got := strings.TrimSpace(buf.String()); !strings.HasSuffix(got, tt.want) {
+			t.Errorf("mode %d:\n got %s\nwant suffix %s", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestWithDedup_BuiltinKeys(t *testing.T) {
+	tests := []struct {
+		mode DedupMode
+		want string
+	}{
+		{DedupKeepLast, `{"level":"INFO","msg":"m","n":1}`},
+		{DedupKeepFirst, `{"level":"INFO","msg":"m","n":1}`},
+		{DedupSuffix, `{"level":"INFO","msg":"m","msg#1":"dup","level#1":"x","n":1,"msg#2":"again"}`},
+	}
+	for _, tt := range tests {
+		buf := &bytes.Buffer{}
+		l, err := New(WithWriter(&testWriter{buf: buf}), WithFormatter(JSON(WithoutTime())), WithDedup(tt.mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.With("msg", "dup").Info("m", "level", "x", "n", 1, "msg", "again")
+		if got := strings.TrimSpace(buf.String()); got != tt.want {
+			t.Errorf("mode %d:\n got %s\nwant %s", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestWithDedup_AllFormatters(t *testing.T) {
+	for _, name := range []string{"json", "text", "logfmt", "fastjson", "color", "console", "ecs"} {
+		f, _ := formatterByName(name)
+		buf := &bytes.Buffer{}
+		l, err := New(WithWriter(&testWriter{buf: buf}), WithFormatter(f), WithDedup(DedupKeepLast))
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.With("request_id", "first").Info("msg", "request_id", "second")
+		out := stripANSI(buf.String())
+		if strings.Count(out, "request_id") != 1 || !strings.Contains(out, "second") {
+			t.Errorf("%s should keep one request_id: %s", name, out)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package s_log
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+type stackError struct {
+	msg string
+	err error
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string {
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *stackError) Unwrap() error { return e.err }
+
+func (e *stackError) stackPCs() []uintptr { return e.pcs }
+
+// WrapErr annotates err with msg and the stack of the caller. It returns nil
+// if err is nil.
+func WrapErr(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{msg: msg, err: err, pcs: callers(2)}
+}
+
+// WithErrorDetails expands error attributes into msg, type, the chain of
+// wrapped errors and, when one was captured, the stack.
+func WithErrorDetails() FormatOption {
+	return func(o *formatOptions) { o.errorDetails = true }
+}
+
+func (o *formatOptions) expandError(a slog.Attr) slog.Attr {
+	if !o.errorDetails || a.Value.Kind() != slog.KindAny {
+		return a
+	}
+	if err, ok := a.Value.Any().(error); ok {
+		a.Value = errorValue(err)
+	}
+	return a
+}
+
+func errorValue(err error) slog.Value {
+	attrs := []slog.Attr{slog.String("msg", err.Error()), slog.String("type", fmt.Sprintf("%T", err))}
+	if chain := errorChain(err); len(chain) > 0 {
+		attrs = append(attrs, slog.Any("chain", chain))
+	}
+	if stack := stackOf(errorPCs(err)); len(stack) > 0 {
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// errorChain lists the errors reachable through Unwrap, depth first.
+func errorChain(err error) []string {
+	var chain []string
+	walkErrors(err, func(e error) {
+		if e != err {
+			chain = append(chain, fmt.Sprintf("%T: %s", e, e.Error()))
+		}
+	})
+	return chain
+}
+
+func walkErrors(err error, fn func(error)) {
+	fn(err)
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			if e != nil {
+				walkErrors(e, fn)
+			}
+		}
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			walkErrors(e, fn)
+		}
+	}
+}
+
+// errorPCs returns the deepest stack in the chain, captured either by
+// WrapErr or by a pkg/errors style StackTrace method.
+func errorPCs(err error) []uintptr {
+	var pcs []uintptr
+	walkErrors(err, func(e error) {
+		if p := stackPCs(e); len(p) > 0 {
+			pcs = p
+		}
+	})
+	return pcs
+}
+
+func stackPCs(err error) []uintptr {
+	if s, ok := err.(interface{ stackPCs() []uintptr }); ok {
+		return s.stackPCs()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	if t := m.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,118 @@
+package s_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type pkgFrame uintptr
+
+type pkgStackTrace []pkgFrame
+
+type pkgError struct {
+	msg   string
+	stack []uintptr
+}
+
+func (e *pkgError) Error() string { return e.msg }
+
+func (e *pkgError) StackTrace() pkgStackTrace {
+	st := make(pkgStackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		st[i] = pkgFrame(pc)
+	}
+	return st
+}
+
+func newPkgError(msg string) error {
+	pcs := make([]uintptr, 32)
+	return &pkgError{msg: msg, stack: pcs[:runtime.Callers(1, pcs)]}
+}
+
+func TestWrapErr(t *testing.T) {
+	if WrapErr(nil, "x") != nil {
+		t.Error("WrapErr(nil) should be nil")
+	}
+	base := fs.ErrNotExist
+	err := WrapErr(base, "load config")
+	if err.Error() != "load config: file does not exist" || !errors.Is(err, base) {
+		t.Errorf("unexpected wrapped error: %v", err)
+	}
+	stack := stackOf(errorPCs(err))
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestWrapErr") {
+		t.Errorf("stack should start at the caller: %v", stack)
+	}
+	if WrapErr(base, "").Error() != base.Error() {
+		t.Error("empty message should keep the error text")
+	}
+}
+
+func TestErrorChainAndStack(t *testing.T) {
+	inner := newPkgError("disk")
+	err := fmt.Errorf("save: %w", errors.Join(inner, errors.New("other")))
+
+	chain := errorChain(err)
+	if len(chain) != 3 || chain[1] != "*s_log.pkgError: disk" || chain[2] != "*errors.errorString: other" {
+		t.Errorf("unexpected chain: %q", chain)
+	}
+	stack := stackOf(errorPCs(err))
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "newPkgError") {
+		t.Errorf("pkg/errors style stack should be used: %v", stack)
+	}
+	if errorPCs(errors.New("plain")) != nil {
+		t.Error("plain errors have no stack")
+	}
+}
+
+func TestWithErrorDetails(t *testing.T) {
+	err := WrapErr(errors.New("boom"), "query")
+	formatters := map[string]Formatter{
+		"json":     JSON(WithErrorDetails()),
+		"fastjson": FastJSON(WithErrorDetails()),
+		"text":     Text(WithErrorDetails()),
+		"logfmt":   Logfmt(WithErrorDetails()),
+		"color":    ColorText(WithErrorDetails(), WithColor(ColorNever)),
+		"console":  Console(WithErrorDetails(), WithColor(ColorNever)),
+		"ecs":      ECS(WithErrorDetails()),
+	}
+	for name, f := range formatters {
+		buf := &bytes.Buffer{}
+		slog.New(f.Format(buf, nil)).WithGroup("g").Error("failed", "cause", err)
+		output := buf.String()
+		for _, want := range []string{"query: boom", "*s_log.stackError", "*errors.errorString: boom", "TestWithErrorDetails"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s: output should contain %q: %s", name, want, output)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	slog.New(FastJSON(WithErrorDetails()).Format(buf, nil)).Error("failed", "err", err)
+	var m struct {
+		Err struct {
+			Msg   string   `json:"msg"`
+			Type  string   `json:"type"`
+			Chain []string `json:"chain"`
+			Stack Stack    `json:"stack"`
+		} `json:"err"`
+	}
+	if e := json.Unmarshal(buf.Bytes(), &m); e != nil {
+		t.Fatalf("invalid JSON: %v", e)
+	}
+	if m.Err.Msg != "query: boom" || len(m.Err.Chain) != 1 || len(m.Err.Stack) == 0 || m.Err.Stack[0].Line == 0 {
+		t.Errorf("unexpected structured error: %+v", m.Err)
+	}
+
+	buf.Reset()
+	slog.New(JSON().Format(buf, nil)).Error("failed", "err", err)
+	if !strings.Contains(buf.String(), `"err":"query: boom"`) {
+		t.Errorf("errors should stay plain without the option: %s", buf.String())
+	}
+}
@@ -0,0 +1,321 @@
+package s_log
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type fastJSONFormatter struct {
+	fo formatOptions
+}
+
+func (f *fastJSONFormatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	h := &fastJSONHandler{w: w, mu: &sync.Mutex{}, fo: &f.fo}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+var fastJSONFmt = &fastJSONFormatter{}
+
+// FastJSON is a drop-in replacement for JSON that encodes records without
+// reflection for the common kinds and reuses pooled buffers.
+func FastJSON(opts ...FormatOption) Formatter {
+	if len(opts) == 0 {
+		return fastJSONFmt
+	}
+	return &fastJSONFormatter{fo: newFormatOptions(opts)}
+}
+
+const maxPooledBuf = 64 << 10
+
+var bufPool = sync.Pool{New: func() any {
+	b := make([]byte, 0, 1024)
+	return &b
+}}
+
+func getBuf() *[]byte { return bufPool.Get().(*[]byte) }
+
+func putBuf(b *[]byte) {
+	if cap(*b) > maxPooledBuf {
+		return
+	}
+	*b = (*b)[:0]
+	bufPool.Put(b)
+}
+
+type fastJSONHandler struct {
+	w    io.Writer
+	mu   *sync.Mutex
+	opts slog.HandlerOptions
+	fo   *formatOptions
+	// prefix holds the encoded WithAttrs output, including the opening of
+	// the first nOpen groups.
+	prefix []byte
+	groups []string
+	nOpen  int
+}
+
+func (h *fastJSONHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *fastJSONHandler) Handle(ctx context.Context, r slog.Record) error {
+	bp := getBuf()
+	buf := append(h.encode((*bp)[:0], r), '\n')
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+	*bp = buf
+	putBuf(bp)
+	return err
+}
+
+func (h *fastJSONHandler) encode(buf []byte, r slog.Record) []byte {
+	buf = append(buf, '{')
+
+	if !r.Time.IsZero() && !h.fo.omitTime {
+		buf = h.appendAttr(buf, nil, slog.Time(slog.TimeKey, r.Time))
+	}
+	if h.opts.ReplaceAttr == nil {
+		buf = appendJSONString(appendJSONKey(buf, slog.LevelKey), levelName(r.Level))
+	} else {
+		buf = h.appendAttr(buf, nil, slog.Any(slog.LevelKey, r.Level))
+	}
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		buf = h.appendAttr(buf, nil, slog.Any(slog.SourceKey, &slog.Source{Function: f.Function, File: f.File, Line: f.Line}))
+	}
+	buf = h.appendAttr(buf, nil, slog.String(slog.MessageKey, r.Message))
+
+	if len(h.prefix) > 0 {
+		buf = append(appendSep(buf), h.prefix...)
+	}
+	closing := h.nOpen
+	if r.NumAttrs() > 0 {
+		for _, g := range h.groups[h.nOpen:] {
+			buf = append(appendJSONKey(buf, g), '{')
+		}
+		closing = len(h.groups)
+		r.Attrs(func(a slog.Attr) bool {
+			buf = h.appendAttr(buf, h.groups, a)
+			return true
+		})
+	}
+	for ; closing > 0; closing-- {
+		buf = append(buf, '}')
+	}
+	return append(buf, '}')
+}
+
+func (h *fastJSONHandler) appendAttr(buf []byte, groups []string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		if h.opts.ReplaceAttr != nil {
+			a = h.opts.ReplaceAttr(groups, a)
+			a.Value = a.Value.Resolve()
+			if a.Equal(slog.Attr{}) {
+				return buf
+			}
+		}
+		if a = h.fo.expandError(a); groups == nil && a.Key == slog.SourceKey {
+			a = h.fo.sourceAttr(a)
+		}
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return buf
+		}
+		if a.Key != "" {
+			buf = append(appendJSONKey(buf, a.Key), '{')
+			if h.opts.ReplaceAttr != nil {
+				groups = append(groups[:len(groups):len(groups)], a.Key)
+			}
+		}
+		for _, ga := range attrs {
+			buf = h.appendAttr(buf, groups, ga)
+		}
+		if a.Key != "" {
+			buf = append(buf, '}')
+		}
+		return buf
+	}
+	buf = appendJSONKey(buf, a.Key)
+	if groups == nil && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+		return h.appendTime(buf, a.Value.Time())
+	}
+	return h.appendValue(buf, a.Value)
+}
+
+func (h *fastJSONHandler) appendTime(buf []byte, t time.Time) []byte {
+	if h.fo.timeLoc != nil {
+		t = t.In(h.fo.timeLoc)
+	}
+	switch h.fo.timeLayout {
+	case "":
+		return append(t.AppendFormat(append(buf, '"'), time.RFC3339Nano), '"')
+	case TimeUnix:
+		return strconv.AppendInt(buf, t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	case TimeUnixNano:
+		return strconv.AppendInt(buf, t.UnixNano(), 10)
+	}
+	return appendJSONString(buf, t.Format(h.fo.timeLayout))
+}
+
+func (h *fastJSONHandler) appendValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendJSONString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return appendJSONFloat(buf, v.Float64())
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		if h.fo.durationUnit > 0 {
+			return appendJSONFloat(buf, float64(v.Duration())/float64(h.fo.durationUnit))
+		}
+		return strconv.AppendInt(buf, int64(v.Duration()), 10)
+	case slog.KindTime:
+		return append(v.Time().AppendFormat(append(buf, '"'), time.RFC3339Nano), '"')
+	}
+	switch x := v.Any().(type) {
+	case slog.Level:
+		return appendJSONString(buf, levelName(x))
+	case *slog.Source:
+		buf = appendJSONString(appendJSONKey(append(buf, '{'), "function"), x.Function)
+		buf = appendJSONString(appendJSONKey(buf, "file"), x.File)
+		return append(strconv.AppendInt(appendJSONKey(buf, "line"), int64(x.Line), 10), '}')
+	case json.Marshaler:
+		if b, err := x.MarshalJSON(); err == nil {
+			return append(buf, b...)
+		}
+	case error:
+		return appendJSONString(buf, x.Error())
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			return appendJSONString(buf, string(b))
+		}
+	}
+	var bb bytes.Buffer
+	enc := json.NewEncoder(&bb)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v.Any()); err != nil {
+		return appendJSONString(buf, fmt.Sprintf("!ERROR:%v", err))
+	}
+	return append(buf, bytes.TrimRight(bb.Bytes(), "\n")...)
+}
+
+func appendJSONFloat(buf []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.AppendQuote(buf, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, 64)
+}
+
+func appendSep(buf []byte) []byte {
+	if n := len(buf); n > 0 && buf[n-1] != '{' {
+		return append(buf, ',')
+	}
+	return buf
+}
+
+func appendJSONKey(buf []byte, key string) []byte {
+	return append(appendJSONString(appendSep(buf), key), ':')
+}
+
+const hexDigits = "0123456789abcdef"
+
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(append(buf, s[start:i]...), `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(append(buf, s[start:i]...), '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(append(buf, s[start:]...), '"')
+}
+
+func (h *fastJSONHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	buf := slices.Clip(h.prefix)
+	for _, g := range h.groups[h.nOpen:] {
+		buf = append(appendJSONKey(buf, g), '{')
+	}
+	h2.nOpen = len(h.groups)
+	for _, a := range attrs {
+		buf = h.appendAttr(buf, h.groups, a)
+	}
+	h2.prefix = buf
+	return &h2
+}
+
+func (h *fastJSONHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	return &h2
+}
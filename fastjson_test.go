@@ -0,0 +1,115 @@
+package s_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeLines(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var m map[string]any
+		if err := json.Unmarshal(line, &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// fixedTime sets the same time on every record so outputs can be compared
+// including the time field.
+type fixedTime struct{ slog.Handler }
+
+func (h fixedTime) Handle(ctx context.Context, r slog.Record) error {
+	r.Time = time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("", 8*3600))
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h fixedTime) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return fixedTime{h.Handler.WithAttrs(attrs)}
+}
+
+func (h fixedTime) WithGroup(name string) slog.Handler { return fixedTime{h.Handler.WithGroup(name)} }
+
+func TestFastJSON_MatchesJSON(t *testing.T) {
+	logAll := func(l *slog.Logger) {
+		l.Info("plain")
+		l.Info("kinds", "s", "a\"b\\c\n\t\x01<>& \xff", "i", -3, "u", uint64(7), "f", 1.5, "b", true,
+			"d", time.Second, "t", time.Unix(0, 0).UTC(), "err", errors.New("boom"), "ip", net.IPv4(1, 2, 3, 4),
+			"m", map[string]int{"x": 1}, "nil", nil, slog.Group("empty"))
+		g := l.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h")
+		g.Warn("nested", "c", 3, slog.Group("i", "d", 4), slog.Group("", "e", 5))
+		g.Warn("no attrs")
+		l.WithGroup("unused").Info("pending group")
+	}
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	want, got := &bytes.Buffer{}, &bytes.Buffer{}
+	logAll(slog.New(fixedTime{JSON().Format(want, opts)}))
+	logAll(slog.New(fixedTime{FastJSON().Format(got, opts)}))
+
+	if w, g := decodeLines(t, want.Bytes()), decodeLines(t, got.Bytes()); !reflect.DeepEqual(w, g) {
+		t.Errorf("FastJSON output differs from JSON:\nwant %s\ngot  %s", want.String(), got.String())
+	}
+}
+
+func TestFastJSON_Options(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := FastJSON(WithTimeFormat(TimeUnix), WithDurationUnit(time.Millisecond)).Format(buf, &slog.HandlerOptions{
+		AddSource: true,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "secret" {
+				return slog.Attr{}
+			}
+			if len(groups) == 1 && a.Key == "x" {
+				return slog.String("x", groups[0])
+			}
+			return a
+		},
+	})
+	slog.New(h).WithGroup("g").Info("m", "secret", 1, "x", 0, "d", 1500*time.Microsecond, "nan", math.NaN())
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if _, ok := m["time"].(float64); !ok {
+		t.Errorf("time should be unix seconds: %s", buf.String())
+	}
+	if src, _ := m["source"].(map[string]any); !strings.HasSuffix(src["file"].(string), "fastjson_test.go") {
+		t.Errorf("unexpected source: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"g":{"x":"g","d":1.5,"nan":"NaN"}`) {
+		t.Errorf("unexpected attrs: %s", buf.String())
+	}
+}
+
+func TestFastJSON_ZeroAlloc(t *testing.T) {
+	h := FastJSON().Format(io.Discard, nil).WithAttrs([]slog.Attr{slog.String("svc", "api")})
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "request", 0)
+	r.AddAttrs(slog.String("method", "GET"), slog.String("path", "/v1/users"), slog.Int("status", 200),
+		slog.Duration("elapsed", time.Millisecond), slog.Bool("cached", false), slog.Float64("ratio", 0.5),
+		slog.Int64("bytes", 1024), slog.String("ip", "10.0.0.1"))
+	ctx := context.Background()
+
+	if n := testing.AllocsPerRun(100, func() { _ = h.Handle(ctx, r) }); n != 0 {
+		t.Errorf("Handle allocated %v times per call", n)
+	}
+	logger := slog.New(FastJSON().Format(io.Discard, nil))
+	if n := testing.AllocsPerRun(100, func() {
+		logger.Info("request", "method", "GET", "status", 200, "cached", true, "path", "/v1")
+	}); n != 0 {
+		t.Errorf("Info allocated %v times per call", n)
+	}
+}
@@ -5,32 +5,173 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type Formatter interface {
 	Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler
 }
 
+type FormatOption func(*formatOptions)
+
+type formatOptions struct {
+	keyOrder     []string
+	timeLayout   string
+	timeLoc      *time.Location
+	omitTime     bool
+	durationUnit time.Duration
+	indent       string
+	color        ColorMode
+	theme        *Theme
+	console      bool
+	errorDetails bool
+	sourceStyle  SourceStyle
+	sourceFunc   bool
+}
+
+const (
+	TimeUnix      = "unix"
+	TimeUnixMilli = "unixmilli"
+	TimeUnixNano  = "unixnano"
+)
+
+func newFormatOptions(opts []FormatOption) formatOptions {
+	var o formatOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func WithKeyOrder(keys ...string) FormatOption {
+	return func(o *formatOptions) { o.keyOrder = keys }
+}
+
+func WithTimeFormat(layout string) FormatOption {
+	return func(o *formatOptions) { o.timeLayout = layout }
+}
+
+func WithTimeZone(loc *time.Location) FormatOption {
+	return func(o *formatOptions) { o.timeLoc = loc }
+}
+
+func WithUTC() FormatOption {
+	return WithTimeZone(time.UTC)
+}
+
+func WithoutTime() FormatOption {
+	return func(o *formatOptions) { o.omitTime = true }
+}
+
+func WithDurationUnit(unit time.Duration) FormatOption {
+	return func(o *formatOptions) { o.durationUnit = unit }
+}
+
+// WithIndent makes ColorJSON print each record over multiple lines, nesting
+// objects with the given indent.
+func WithIndent(indent string) FormatOption {
+	return func(o *formatOptions) { o.indent = indent }
+}
+
+func (o *formatOptions) keyRank(key string) int {
+	for i, k := range o.keyOrder {
+		if k == key {
+			return i
+		}
+	}
+	return len(o.keyOrder)
+}
+
+func (o *formatOptions) customTime() bool {
+	return o.omitTime || o.timeLoc != nil || o.timeLayout != ""
+}
+
+// timeValue renders the record time; an empty layout keeps it a time so
+// each handler can apply its own default format.
+func (o *formatOptions) timeValue(t time.Time) slog.Value {
+	if o.timeLoc != nil {
+		t = t.In(o.timeLoc)
+	}
+	switch o.timeLayout {
+	case "":
+		return slog.TimeValue(t)
+	case TimeUnix:
+		return slog.Int64Value(t.Unix())
+	case TimeUnixMilli:
+		return slog.Int64Value(t.UnixMilli())
+	case TimeUnixNano:
+		return slog.Int64Value(t.UnixNano())
+	}
+	return slog.StringValue(t.Format(o.timeLayout))
+}
+
 type formatter struct {
 	fn func(io.Writer, *slog.HandlerOptions) slog.Handler
+	fo formatOptions
 }
 
 func (f *formatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
-	return f.fn(w, opts)
+	return f.fn(w, withFormatOptions(opts, &f.fo))
+}
+
+func (f *formatter) with(opts []FormatOption) Formatter {
+	if len(opts) == 0 {
+		return f
+	}
+	return &formatter{fn: f.fn, fo: newFormatOptions(opts)}
+}
+
+func withFormatOptions(opts *slog.HandlerOptions, fo *formatOptions) *slog.HandlerOptions {
+	o := slog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	old := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if old != nil {
+			a = old(groups, a)
+		}
+		if a = fo.expandError(a); len(groups) > 0 {
+			return a
+		}
+		switch a.Key {
+		case slog.LevelKey:
+			if lv, ok := a.Value.Any().(slog.Level); ok {
+				return slog.String(a.Key, levelName(lv))
+			}
+		case slog.TimeKey:
+			if a.Value.Kind() == slog.KindTime && fo.customTime() {
+				if fo.omitTime {
+					return slog.Attr{}
+				}
+				a.Value = fo.timeValue(a.Value.Time())
+			}
+		case slog.SourceKey:
+			return fo.sourceAttr(a)
+		}
+		return a
+	}
+	return &o
 }
 
 type colorTextHandler struct {
-	w         io.Writer
-	opts      *slog.HandlerOptions
-	level     *slog.LevelVar
-	groups    []string
-	workDir   string
-	workDirOK bool
+	w      io.Writer
+	opts   *slog.HandlerOptions
+	fo     *formatOptions
+	theme  *Theme
+	level  *slog.LevelVar
+	attrs  []boundAttr
+	prefix string
+}
+
+type boundAttr struct {
+	prefix string
+	a      slog.Attr
 }
 
 func (h *colorTextHandler) Enabled(ctx context.Context, level slog.Level) bool {
@@ -38,30 +179,130 @@ func (h *colorTextHandler) Enabled(ctx context.Context, level slog.Level) bool {
 }
 
 func (h *colorTextHandler) Handle(ctx context.Context, r slog.Record) error {
-	buf := make([]byte, 0, 512)
-	buf = append(append(append(buf, "time="...), r.Time.AppendFormat(nil, timeFormat)...), ' ')
-	buf = append(append(append(append(append(buf, "level="...), bold...), getLevelColor(r.Level)...), r.Level.String()...), reset...)
-	buf = append(buf, ' ')
-	h.writeColored(&buf, fgCyan, r.Message)
+	bp := getBuf()
+	buf := *bp
+	if h.fo.console {
+		h.appendConsole(&buf, r)
+	} else {
+		h.appendText(&buf, r)
+	}
+	_, err := h.w.Write(buf)
+	*bp = buf
+	putBuf(bp)
+	return err
+}
+
+func (h *colorTextHandler) appendText(buf *[]byte, r slog.Record) {
+	if !r.Time.IsZero() && !h.fo.omitTime {
+		tc := h.theme.timestamp()
+		*buf = h.appendTime(append(append(*buf, "time="...), tc...), r.Time)
+		if tc != "" {
+			*buf = append(*buf, reset...)
+		}
+		*buf = append(*buf, ' ')
+	}
+	*buf = append(*buf, "level="...)
+	h.appendLevel(buf, r.Level, levelName(r.Level))
+	*buf = append(*buf, ' ')
+	h.writeColored(buf, h.theme.message(), messageText(r.Message))
+	var deferred []boundAttr
+	h.appendAttrs(buf, r, h.theme.key(), &deferred)
+	if src := h.source(r); src != "" {
+		*buf = append(*buf, ' ')
+		h.writeColored(buf, h.theme.source(), "source=")
+		h.writeColored(buf, h.theme.source(), strconv.Quote(src))
+	}
+	*buf = append(*buf, '\n')
+	h.appendDeferred(buf, deferred)
+}
+
+func (h *colorTextHandler) appendLevel(buf *[]byte, lv slog.Level, name string) {
+	if h.theme == nil {
+		*buf = append(*buf, name...)
+		return
+	}
+	*buf = append(append(h.theme.appendLevel(*buf, lv), name...), reset...)
+}
+
+// appendAttrs writes the bound and record attributes. When deferred is
+// non-nil, multi-line values are collected there instead of written.
+func (h *colorTextHandler) appendAttrs(buf *[]byte, r slog.Record, keyColor string, deferred *[]boundAttr) {
+	for _, ba := range h.attrs {
+		h.appendAttr(buf, ba.prefix, ba.a, keyColor, deferred)
+	}
 	r.Attrs(func(a slog.Attr) bool {
 		if !builtinKeys[a.Key] {
-			buf = append(buf, ' ')
-			h.writeColored(&buf, fgBlue, a.Key)
-			buf = append(buf, '=')
-			h.appendValue(&buf, a.Value, fgCyan)
+			h.appendAttr(buf, h.prefix, a, keyColor, deferred)
 		}
 		return true
 	})
-	if h.opts != nil && h.opts.AddSource && r.PC != 0 {
-		if f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next(); f.File != "" {
-			buf = append(buf, ' ')
-			h.writeColored(&buf, fgGray, "source=")
-			h.writeColored(&buf, fgGray, strconv.Quote(h.formatSourcePath(f.File)+":"+strconv.Itoa(f.Line)))
+}
+
+func (h *colorTextHandler) source(r slog.Record) string {
+	if h.opts == nil || !h.opts.AddSource || r.PC == 0 {
+		return ""
+	}
+	f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+	if f.File == "" {
+		return ""
+	}
+	return h.fo.sourceText(&slog.Source{Function: f.Function, File: f.File, Line: f.Line}, SourceRelative)
+}
+
+func (h *colorTextHandler) appendTime(buf []byte, t time.Time) []byte {
+	switch v := h.fo.timeValue(t); v.Kind() {
+	case slog.KindTime:
+		return v.Time().AppendFormat(buf, timeFormat)
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	default:
+		return appendLogfmtString(buf, v.String())
+	}
+}
+
+func (h *colorTextHandler) appendAttr(buf *[]byte, prefix string, a slog.Attr, keyColor string, deferred *[]boundAttr) {
+	a.Value = a.Value.Resolve()
+	a = h.fo.expandError(a)
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
 		}
+		for _, ga := range a.Value.Group() {
+			h.appendAttr(buf, prefix, ga, keyColor, deferred)
+		}
+		return
 	}
-	buf = append(buf, '\n')
-	_, err := h.w.Write(buf)
-	return err
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if deferred != nil && (h.fo.console && isMultiline(a.Value) || isStack(a.Value)) {
+		*deferred = append(*deferred, boundAttr{prefix: prefix, a: a})
+		return
+	}
+	*buf = append(append(*buf, ' '), keyColor...)
+	if prefix != "" {
+		*buf = appendLogfmtKey(*buf, prefix)
+	}
+	*buf = appendLogfmtKey(*buf, a.Key)
+	if keyColor != "" {
+		*buf = append(*buf, reset...)
+	}
+	*buf = append(*buf, '=')
+	h.appendValue(buf, a.Key, a.Value)
+}
+
+// messageText returns msg as is unless it holds control characters or
+// invalid UTF-8, which could forge extra records; such messages are quoted.
+func messageText(msg string) string {
+	for i := 0; i < len(msg); i++ {
+		if msg[i] < ' ' || msg[i] == 0x7f {
+			return string(appendJSONString(nil, msg))
+		}
+	}
+	if !utf8.ValidString(msg) {
+		return string(appendJSONString(nil, msg))
+	}
+	return msg
 }
 
 func (h *colorTextHandler) writeColored(buf *[]byte, color, text string) {
@@ -72,27 +313,19 @@ func (h *colorTextHandler) writeColored(buf *[]byte, color, text string) {
 	}
 }
 
-func (h *colorTextHandler) formatSourcePath(file string) string {
-	if !h.workDirOK {
-		if wd, err := os.Getwd(); err == nil {
-			h.workDir, h.workDirOK = wd, true
-		}
+func (h *colorTextHandler) valueColor(key string, v slog.Value) string {
+	if h.theme == nil {
+		return ""
 	}
-	if h.workDirOK {
-		if rel, err := filepath.Rel(h.workDir, file); err == nil && len(rel) < len(file) {
-			return rel
-		}
-	}
-	return file
+	return h.theme.valueColor(key, v)
 }
 
-func (h *colorTextHandler) appendValue(buf *[]byte, v slog.Value, color string) {
-	if color != "" {
-		*buf = append(*buf, color...)
-	}
-	switch v = v.Resolve(); v.Kind() {
+func (h *colorTextHandler) appendValue(buf *[]byte, key string, v slog.Value) {
+	color := h.valueColor(key, v)
+	*buf = append(*buf, color...)
+	switch v.Kind() {
 	case slog.KindString:
-		*buf = append(*buf, v.String()...)
+		*buf = appendLogfmtString(*buf, v.String())
 	case slog.KindInt64:
 		*buf = strconv.AppendInt(*buf, v.Int64(), 10)
 	case slog.KindUint64:
@@ -106,14 +339,7 @@ func (h *colorTextHandler) appendValue(buf *[]byte, v slog.Value, color string)
 	case slog.KindTime:
 		*buf = v.Time().AppendFormat(*buf, time.RFC3339Nano)
 	case slog.KindAny:
-		*buf = append(*buf, fmt.Sprint(v.Any())...)
-	case slog.KindLogValuer:
-		h.appendValue(buf, v.LogValuer().LogValue(), color)
-	case slog.KindGroup:
-		for _, a := range v.Group() {
-			*buf = append(append(append(*buf, ' '), a.Key...), '=')
-			h.appendValue(buf, a.Value, color)
-		}
+		*buf = appendLogfmtString(*buf, fmt.Sprint(v.Any()))
 	}
 	if color != "" {
 		*buf = append(*buf, reset...)
@@ -121,62 +347,100 @@ func (h *colorTextHandler) appendValue(buf *[]byte, v slog.Value, color string)
 }
 
 func (h *colorTextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return h
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, boundAttr{prefix: h.prefix, a: a})
+	}
+	return &h2
 }
 
 func (h *colorTextHandler) WithGroup(name string) slog.Handler {
-	return &colorTextHandler{w: h.w, opts: h.opts, level: h.level, groups: append(h.groups, name), workDir: h.workDir, workDirOK: h.workDirOK}
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
 }
 
-type colorFormatter struct{}
+type colorFormatter struct {
+	fo formatOptions
+}
 
 func (f *colorFormatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
 	var lv *slog.LevelVar
 	if opts != nil && opts.Level != nil {
 		lv, _ = opts.Level.(*slog.LevelVar)
 	}
-	wd, err := os.Getwd()
-	return &colorTextHandler{w: w, opts: opts, level: lv, workDir: wd, workDirOK: err == nil}
-}
-
-type colorJSONFormatter struct {
-	fn func(io.Writer, *slog.HandlerOptions) slog.Handler
-}
-
-func (f *colorJSONFormatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
-	if opts == nil {
-		opts = &slog.HandlerOptions{}
-	}
-	old := opts.ReplaceAttr
-	opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
-		if old != nil {
-			a = old(groups, a)
-		}
-		switch a.Key {
-		case slog.LevelKey:
-			if lv, ok := a.Value.Any().(slog.Level); ok {
-				color := getLevelColor(lv)
-				return slog.String(a.Key, bold+color+lv.String()+reset)
-			}
-		case slog.MessageKey:
-			return slog.String(a.Key, fgCyan+a.Value.String()+reset)
-		}
-		return a
-	}
-	return f.fn(w, opts)
+	return &colorTextHandler{w: w, opts: opts, fo: &f.fo, theme: f.fo.activeTheme(w), level: lv}
 }
 
 var (
 	jsonFmt      = &formatter{fn: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewJSONHandler(w, opts) }}
 	textFmt      = &formatter{fn: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewTextHandler(w, opts) }}
 	colorTextFmt = &colorFormatter{}
-	colorJSONFmt = &colorJSONFormatter{fn: func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewJSONHandler(w, opts) }}
+	autoFmt      = &colorFormatter{fo: formatOptions{color: ColorAuto}}
+	consoleFmt   = &colorFormatter{fo: formatOptions{console: true}}
+	colorJSONFmt = &colorJSONFormatter{}
 )
 
-func JSON() Formatter      { return jsonFmt }
-func Text() Formatter      { return textFmt }
-func ColorText() Formatter { return colorTextFmt }
-func ColorJSON() Formatter { return colorJSONFmt }
+func formatterByName(name string) (Formatter, bool) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSON(), true
+	case "text":
+		return Text(), true
+	case "color", "colortext":
+		return ColorText(), true
+	case "colorjson":
+		return ColorJSON(), true
+	case "auto":
+		return Auto(), true
+	case "console":
+		return Console(), true
+	case "logfmt":
+		return Logfmt(), true
+	case "fastjson":
+		return FastJSON(), true
+	case "ecs":
+		return ECS(), true
+	case "gcp":
+		return GCP(), true
+	case "datadog":
+		return Datadog(), true
+	}
+	return nil, false
+}
+
+func JSON(opts ...FormatOption) Formatter { return jsonFmt.with(opts) }
+func Text(opts ...FormatOption) Formatter { return textFmt.with(opts) }
+
+func ColorText(opts ...FormatOption) Formatter {
+	if len(opts) == 0 {
+		return colorTextFmt
+	}
+	return &colorFormatter{fo: newFormatOptions(opts)}
+}
+
+// Auto is ColorText that only colors when the writer is a terminal; see
+// WithColor for the rules.
+func Auto(opts ...FormatOption) Formatter {
+	if len(opts) == 0 {
+		return autoFmt
+	}
+	return &colorFormatter{fo: newFormatOptions(append([]FormatOption{WithColor(ColorAuto)}, opts...))}
+}
+
+func ColorJSON(opts ...FormatOption) Formatter {
+	if len(opts) == 0 {
+		return colorJSONFmt
+	}
+	return &colorJSONFormatter{fo: newFormatOptions(opts)}
+}
 
 const (
 	reset      = "\x1b[0m"
@@ -185,32 +449,15 @@ const (
 	fgGreen    = "\x1b[32m"
 	fgYellow   = "\x1b[33m"
 	fgBlue     = "\x1b[34m"
+	fgMagenta  = "\x1b[35m"
 	fgCyan     = "\x1b[36m"
 	fgGray     = "\x1b[90m"
 	timeFormat = "2006-01-02T15:04:05.000Z07:00"
 )
 
-var (
-	levelColors = map[slog.Level]string{
-		slog.LevelDebug: fgGray,
-		slog.LevelInfo:  fgGreen,
-		slog.LevelWarn:  fgYellow,
-		slog.LevelError: fgRed,
-	}
-	builtinKeys = map[string]bool{
-		slog.LevelKey:   true,
-		slog.MessageKey: true,
-		slog.TimeKey:    true,
-		slog.SourceKey:  true,
-	}
-)
-
-func getLevelColor(level slog.Level) string {
-	if c, ok := levelColors[level]; ok {
-		return c
-	}
-	if level < slog.LevelInfo {
-		return fgGray
-	}
-	return fgRed
+var builtinKeys = map[string]bool{
+	slog.LevelKey:   true,
+	slog.MessageKey: true,
+	slog.TimeKey:    true,
+	slog.SourceKey:  true,
 }
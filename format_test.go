@@ -2,9 +2,13 @@ package s_log
 
 import (
 	"bytes"
+	"encoding/json"
 	"log/slog"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestJSON(t *testing.T) {
@@ -146,3 +150,105 @@ func TestFormatter_Singleton(t *testing.T) {
 		t.Error("ColorJSON() should return singleton")
 	}
 }
+
+func TestColorText_Escaping(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(ColorText().Format(buf, nil))
+	logger.Info("test", "path", "a b=c", "bad key", "ok")
+
+	output := buf.String()
+	if !strings.Contains(output, `"a b=c"`) {
+		t.Errorf("value with spaces should be quoted: %q", output)
+	}
+	if !strings.Contains(output, "bad_key") {
+		t.Errorf("key with spaces should be sanitized: %q", output)
+	}
+
+	for _, f := range []Formatter{ColorText(), Console()} {
+		buf.Reset()
+		slog.New(f.Format(buf, nil)).Info("line1\nlevel=ERROR fake\x00 \xff", "k", "v")
+		output = stripANSI(buf.String())
+		if strings.Count(output, "\n") != 1 || !strings.Contains(output, `"line1\nlevel=ERROR fake\u0000 \ufffd"`) {
+			t.Errorf("message with control characters should be quoted on one line: %q", output)
+		}
+		buf.Reset()
+		slog.New(f.Format(buf, nil)).Info("plain message")
+		if !strings.Contains(stripANSI(buf.String()), " plain message") {
+			t.Errorf("plain messages should stay unquoted: %q", buf.String())
+		}
+	}
+}
+
+var ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func stripANSI(s string) string { return ansiRe.ReplaceAllString(s, "") }
+
+func TestColorJSON_Tokens(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(ColorJSON().Format(buf, nil)).With("svc", "api").WithGroup("req")
+	logger.Warn("hello \"x\"", "n", 1.5, "ok", true, "nil", nil, "list", []int{1, 2}, slog.Group("u", "id", "7"))
+
+	output := buf.String()
+	if strings.Contains(output, `\u001b`) {
+		t.Errorf("escape codes should not be embedded in JSON strings: %q", output)
+	}
+	for _, want := range []string{
+		fgBlue + `"svc"` + reset, fgGreen + `"api"` + reset, bold + fgYellow + `"WARN"` + reset,
+		fgCyan + `"hello \"x\""` + reset, fgYellow + "1.5" + reset, fgMagenta + "true" + reset, fgGray + "null" + reset,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q: %q", want, output)
+		}
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(stripANSI(output)), &m); err != nil {
+		t.Fatalf("stripped output should be valid JSON: %v\n%s", err, output)
+	}
+	if req, _ := m["req"].(map[string]any); req["u"].(map[string]any)["id"] != "7" {
+		t.Errorf("unexpected nesting: %v", m)
+	}
+
+	buf.Reset()
+	slog.New(ColorJSON(WithIndent("  "), WithoutTime()).Format(buf, nil)).Info("m", slog.Group("g", "a", 1), slog.Group("e"))
+	want := "{\n  \"level\": \"INFO\",\n  \"msg\": \"m\",\n  \"g\": {\n    \"a\": 1\n  }\n}\n"
+	if got := stripANSI(buf.String()); got != want {
+		t.Errorf("unexpected indented output:\n%s", got)
+	}
+}
+
+func TestFormatter_TimeOptions(t *testing.T) {
+	loc := time.FixedZone("X", 3*3600)
+	formatters := map[string]func(...FormatOption) Formatter{
+		"json": JSON, "text": Text, "color": ColorText, "colorjson": ColorJSON, "logfmt": Logfmt,
+	}
+	for name, newFmt := range formatters {
+		if newFmt(WithUTC()) == newFmt() {
+			t.Errorf("%s: options should not return the singleton", name)
+		}
+
+		buf := &bytes.Buffer{}
+		slog.New(newFmt(WithTimeZone(loc), WithTimeFormat(time.RFC3339)).Format(buf, nil)).Info("m")
+		if !strings.Contains(buf.String(), "+03:00") {
+			t.Errorf("%s: time should use the configured zone: %s", name, buf.String())
+		}
+
+		buf.Reset()
+		before := time.Now().UnixMilli()
+		slog.New(newFmt(WithTimeFormat(TimeUnixMilli)).Format(buf, nil)).Info("m")
+		output := stripANSI(buf.String())
+		i := strings.Index(output, "time")
+		if i < 0 {
+			t.Fatalf("%s: missing time: %s", name, output)
+		}
+		digits := strings.TrimLeft(output[i+4:], `"=:`)
+		if len(digits) < 13 || digits[:13] < strconv.FormatInt(before, 10) {
+			t.Errorf("%s: expected unix millis, got %s", name, buf.String())
+		}
+
+		buf.Reset()
+		slog.New(newFmt(WithoutTime()).Format(buf, nil)).Info("m")
+		if strings.Contains(buf.String(), "time") {
+			t.Errorf("%s: time should be omitted: %s", name, buf.String())
+		}
+	}
+}
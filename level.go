@@ -0,0 +1,432 @@
+package s_log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const EnvLoggerLevels = "LOG_LEVELS"
+
+const (
+	LevelTrace  = slog.Level(-8)
+	LevelNotice = slog.Level(2)
+	LevelFatal  = slog.Level(12)
+	LevelPanic  = slog.Level(16)
+)
+
+type levelInfo struct {
+	name, color string
+}
+
+var (
+	levelsMu sync.RWMutex
+	levelMap = map[string]slog.Level{
+		"TRACE":  LevelTrace,
+		"DEBUG":  slog.LevelDebug,
+		"INFO":   slog.LevelInfo,
+		"NOTICE": LevelNotice,
+		"WARN":   slog.LevelWarn,
+		"ERROR":  slog.LevelError,
+		"FATAL":  LevelFatal,
+		"PANIC":  LevelPanic,
+	}
+	levelInfos = map[slog.Level]levelInfo{
+		LevelTrace:      {"TRACE", fgGray},
+		slog.LevelDebug: {"DEBUG", fgGray},
+		slog.LevelInfo:  {"INFO", fgGreen},
+		LevelNotice:     {"NOTICE", fgCyan},
+		slog.LevelWarn:  {"WARN", fgYellow},
+		slog.LevelError: {"ERROR", fgRed},
+		LevelFatal:      {"FATAL", fgMagenta},
+		LevelPanic:      {"PANIC", fgMagenta},
+	}
+)
+
+func RegisterLevel(level slog.Level, name, color string) {
+	name = strings.ToUpper(name)
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	if old, ok := levelInfos[level]; ok {
+		delete(levelMap, old.name)
+	}
+	if prev, ok := levelMap[name]; ok && prev != level {
+		delete(levelInfos, prev)
+	}
+	levelMap[name] = level
+	levelInfos[level] = levelInfo{name: name, color: color}
+}
+
+var levelAliases = map[string]string{
+	"WARNING":  "WARN",
+	"ERR":      "ERROR",
+	"CRIT":     "FATAL",
+	"CRITICAL": "FATAL",
+}
+
+func ParseLevel(s string) (slog.Level, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return slog.Level(n), nil
+	}
+	name := strings.ToUpper(s)
+	// Registered names may themselves contain + or -, so they are tried
+	// before splitting off an offset.
+	if lv, ok := levelByName(name); ok {
+		return lv, nil
+	}
+	i := strings.LastIndexAny(name, "+-")
+	if i <= 0 {
+		return slog.LevelInfo, fmt.Errorf("s_log: unknown level %q", s)
+	}
+	offset, err := strconv.Atoi(name[i:])
+	if err != nil {
+		return slog.LevelInfo, fmt.Errorf("s_log: unknown level %q", s)
+	}
+	lv, ok := levelByName(name[:i])
+	if !ok {
+		return slog.LevelInfo, fmt.Errorf("s_log: unknown level %q", s)
+	}
+	return lv + slog.Level(offset), nil
+}
+
+func levelByName(name string) (slog.Level, bool) {
+	if alias, ok := levelAliases[name]; ok {
+		name = alias
+	}
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	lv, ok := levelMap[name]
+	return lv, ok
+}
+
+func lookupLevel(s string) (slog.Level, bool) {
+	lv, err := ParseLevel(s)
+	return lv, err == nil
+}
+
+func levelName(level slog.Level) string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if info, ok := levelInfos[level]; ok {
+		return info.name
+	}
+	base, found := level, false
+	for lv := range levelInfos {
+		if lv <= level && (!found || lv > base) {
+			base, found = lv, true
+		}
+	}
+	if !found {
+		for lv := range levelInfos {
+			if !found || lv < base {
+				base, found = lv, true
+			}
+		}
+	}
+	if !found {
+		return level.String()
+	}
+	return fmt.Sprintf("%s%+d", levelInfos[base].name, level-base)
+}
+
+func getLevelColor(level slog.Level) string {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	if info, ok := levelInfos[level]; ok && info.color != "" {
+		return info.color
+	}
+	if level < slog.LevelInfo {
+		return fgGray
+	}
+	return fgRed
+}
+
+func (l *Logger) setLevel(lv slog.Level) {
+	l.levelMu.Lock()
+	defer l.levelMu.Unlock()
+	if l.levelTimer != nil {
+		l.levelTimer.Stop()
+		l.levelTimer = nil
+	}
+	l.levelGen++
+	l.level.Set(lv)
+}
+
+// SetLevel changes the level at runtime. An unknown level leaves the
+// current level unchanged and returns an error.
+func (l *Logger) SetLevel(level string) error {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.setLevel(lv)
+	return nil
+}
+
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+func SetLevelFor(level string, d time.Duration) (cancel func(), err error) {
+	return std.SetLevelFor(level, d)
+}
+
+// SetLevelFor switches to level for d and then restores the previous level.
+// An unknown level leaves the current level unchanged.
+func (l *Logger) SetLevelFor(level string, d time.Duration) (cancel func(), err error) {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		return func() {}, err
+	}
+	l.levelMu.Lock()
+	from := l.level.Level()
+	if l.levelTimer == nil {
+		l.levelPrev = from
+	} else {
+		l.levelTimer.Stop()
+	}
+	l.levelGen++
+	gen := l.levelGen
+	l.level.Set(lv)
+	l.levelUntil = time.Now().Add(d)
+	l.levelTimer = time.AfterFunc(d, func() { l.revertLevel(gen, "expired") })
+	until := l.levelUntil
+	l.levelMu.Unlock()
+
+	l.logLevelChange("log level escalated", from, lv, slog.Duration("duration", d), slog.Time("until", until))
+	return func() { l.revertLevel(gen, "cancelled") }, nil
+}
+
+func (l *Logger) revertLevel(gen uint64, reason string) {
+	l.levelMu.Lock()
+	if l.levelTimer == nil || gen != l.levelGen {
+		l.levelMu.Unlock()
+		return
+	}
+	l.levelTimer.Stop()
+	l.levelTimer = nil
+	from, to := l.level.Level(), l.levelPrev
+	l.level.Set(to)
+	l.levelMu.Unlock()
+
+	l.logLevelChange("log level reverted", from, to, slog.String("reason", reason))
+}
+
+func (l *Logger) logLevelChange(msg string, from, to slog.Level, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{slog.String("from", levelName(from)), slog.String("to", levelName(to))}, attrs...)
+	l.LogAttrs(context.Background(), max(slog.LevelWarn, to), msg, attrs...)
+}
+
+type loggerLevel struct {
+	set atomic.Bool
+	lv  slog.LevelVar
+}
+
+func (l *Logger) namedLevel(name string) *loggerLevel {
+	l.namedMu.Lock()
+	defer l.namedMu.Unlock()
+	ll, ok := l.named[name]
+	if !ok {
+		ll = &loggerLevel{}
+		l.named[name] = ll
+	}
+	return ll
+}
+
+type levelOverrideKey struct{}
+
+type namedHandler struct {
+	slog.Handler
+	level *loggerLevel
+}
+
+func (h *namedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level.set.Load() {
+		return level >= h.level.lv.Level()
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h *namedHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.level.set.Load() {
+		ctx = context.WithValue(ctx, levelOverrideKey{}, true)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *namedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &namedHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *namedHandler) WithGroup(name string) slog.Handler {
+	return &namedHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
+func Named(name string) *slog.Logger {
+	return std.Named(name)
+}
+
+func (l *Logger) Named(name string) *slog.Logger {
+	return slog.New(&namedHandler{Handler: l.Handler(), level: l.namedLevel(name)}).With("logger", name)
+}
+
+func SetLoggerLevel(name, level string) error {
+	return std.SetLoggerLevel(name, level)
+}
+
+func (l *Logger) SetLoggerLevel(name, level string) error {
+	if level == "" {
+		l.namedLevel(name).set.Store(false)
+		return nil
+	}
+	lv, ok := lookupLevel(level)
+	if !ok {
+		return fmt.Errorf("s_log: unknown level %q for logger %q", level, name)
+	}
+	ll := l.namedLevel(name)
+	ll.lv.Set(lv)
+	ll.set.Store(true)
+	return nil
+}
+
+func SetLoggerLevels(spec string) error {
+	return std.SetLoggerLevels(spec)
+}
+
+func (l *Logger) SetLoggerLevels(spec string) error {
+	levels, err := parseLoggerLevels(spec)
+	l.namedMu.Lock()
+	for _, ll := range l.named {
+		ll.set.Store(false)
+	}
+	l.namedMu.Unlock()
+	for name, lv := range levels {
+		ll := l.namedLevel(name)
+		ll.lv.Set(lv)
+		ll.set.Store(true)
+	}
+	return err
+}
+
+func LoggerLevels() map[string]string {
+	return std.LoggerLevels()
+}
+
+func (l *Logger) LoggerLevels() map[string]string {
+	l.namedMu.Lock()
+	defer l.namedMu.Unlock()
+	m := make(map[string]string)
+	for name, ll := range l.named {
+		if ll.set.Load() {
+			m[name] = levelName(ll.lv.Level())
+		}
+	}
+	return m
+}
+
+func parseLoggerLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	var errs []error
+	for _, item := range strings.Split(spec, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(item, "=")
+		name, level = strings.TrimSpace(name), strings.TrimSpace(level)
+		if !ok || name == "" {
+			errs = append(errs, fmt.Errorf("s_log: invalid logger level %q, want name=LEVEL", item))
+			continue
+		}
+		lv, ok := lookupLevel(level)
+		if !ok {
+			errs = append(errs, fmt.Errorf("s_log: unknown level %q for logger %q", level, name))
+			continue
+		}
+		levels[name] = lv
+	}
+	return levels, errors.Join(errs...)
+}
+
+type levelPayload struct {
+	Level    string            `json:"level"`
+	Duration string            `json:"duration,omitempty"`
+	Until    *time.Time        `json:"until,omitempty"`
+	Loggers  map[string]string `json:"loggers,omitempty"`
+}
+
+func LevelHandler() http.Handler {
+	return std.LevelHandler()
+}
+
+func (l *Logger) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var p levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				http.Error(w, "s_log: invalid request body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := l.applyLevelPayload(p); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		p := levelPayload{Level: levelName(l.Level()), Loggers: l.LoggerLevels()}
+		l.levelMu.Lock()
+		if l.levelTimer != nil {
+			until := l.levelUntil
+			p.Until = &until
+		}
+		l.levelMu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p)
+	})
+}
+
+func (l *Logger) applyLevelPayload(p levelPayload) error {
+	var errs []error
+	if _, ok := lookupLevel(p.Level); p.Level != "" && !ok {
+		errs = append(errs, fmt.Errorf("s_log: unknown level %q", p.Level))
+	}
+	var d time.Duration
+	if p.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(p.Duration); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("s_log: invalid duration %q", p.Duration))
+		} else if p.Level == "" {
+			errs = append(errs, errors.New("s_log: duration requires level"))
+		}
+	}
+	for name, level := range p.Loggers {
+		if _, ok := lookupLevel(level); level != "" && !ok {
+			errs = append(errs, fmt.Errorf("s_log: unknown level %q for logger %q", level, name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	switch {
+	case d > 0:
+		_, _ = l.SetLevelFor(p.Level, d)
+	case p.Level != "":
+		_ = l.SetLevel(p.Level)
+	}
+	for name, level := range p.Loggers {
+		_ = l.SetLoggerLevel(name, level)
+	}
+	return nil
+}
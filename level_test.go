@@ -0,0 +1,371 @@
+package s_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNamed(t *testing.T) {
+	defer func() { _ = Close() }()
+	defer func() { _ = SetLoggerLevels("") }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}), WithLevel("INFO"))
+
+	db := Named("db")
+	db.Debug("hidden query")
+	if strings.Contains(buf.String(), "hidden query") {
+		t.Error("debug record should be filtered by global level")
+	}
+
+	if err := SetLoggerLevel("db", "DEBUG"); err != nil {
+		t.Fatalf("SetLoggerLevel() failed: %v", err)
+	}
+	db.Debug("visible query")
+	slog.Debug("global debug")
+	if !strings.Contains(buf.String(), "visible query") {
+		t.Error("named logger override should enable debug")
+	}
+	if !strings.Contains(buf.String(), "logger=db") {
+		t.Errorf("output should contain logger name: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "global debug") {
+		t.Error("override should not affect global logger")
+	}
+}
+
+func TestSetLoggerLevels(t *testing.T) {
+	defer func() { _ = SetLoggerLevels("") }()
+
+	if err := SetLoggerLevels("db=DEBUG, http=warn"); err != nil {
+		t.Fatalf("SetLoggerLevels() failed: %v", err)
+	}
+	levels := LoggerLevels()
+	if levels["db"] != "DEBUG" || levels["http"] != "WARN" {
+		t.Errorf("unexpected levels: %v", levels)
+	}
+
+	if err := SetLoggerLevels("db=LOUD,broken"); err == nil {
+		t.Error("invalid spec should return error")
+	}
+	if len(LoggerLevels()) != 0 {
+		t.Errorf("invalid entries should not be applied: %v", LoggerLevels())
+	}
+}
+
+func TestWithLoggerLevels(t *testing.T) {
+	defer func() { _ = Close() }()
+	defer func() { _ = SetLoggerLevels("") }()
+
+	t.Setenv(EnvLoggerLevels, "cache=ERROR")
+	MustInit()
+	if LoggerLevels()["cache"] != "ERROR" {
+		t.Errorf("env spec should be applied: %v", LoggerLevels())
+	}
+
+	MustInit(WithLoggerLevels("db=DEBUG"))
+	levels := LoggerLevels()
+	if levels["db"] != "DEBUG" || levels["cache"] != "" {
+		t.Errorf("option should replace env spec: %v", levels)
+	}
+	t.Setenv(EnvLoggerLevels, "db=LOUD")
+	l, err := New()
+	if err != nil {
+		t.Fatalf("New should ignore %s: %v", EnvLoggerLevels, err)
+	}
+	if levels := l.LoggerLevels(); len(levels) != 0 {
+		t.Errorf("New should not pick up env levels: %v", levels)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer func() { _ = Close() }()
+	defer func() { _ = SetLoggerLevels("") }()
+
+	MustInit(WithLevel("INFO"))
+	h := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/level", nil))
+	var p levelPayload
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if p.Level != "INFO" {
+		t.Errorf("expected INFO, got %s", p.Level)
+	}
+
+	rec = httptest.NewRecorder()
+	body := `{"level":"debug","loggers":{"db":"WARN"}}`
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if levelVar.Level() != slog.LevelDebug {
+		t.Errorf("expected DEBUG, got %v", levelVar.Level())
+	}
+	if LoggerLevels()["db"] != "WARN" {
+		t.Errorf("expected db=WARN, got %v", LoggerLevels())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"LOUD"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+	if levelVar.Level() != slog.LevelDebug {
+		t.Error("invalid request should not change level")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/level", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestSetLevelFor(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &lockedBuffer{}
+	MustInit(WithWriter(buf), WithLevel("INFO"))
+
+	if _, err := SetLevelFor("DEBUG", 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if levelVar.Level() != slog.LevelDebug {
+		t.Fatalf("expected DEBUG, got %v", levelVar.Level())
+	}
+	if !strings.Contains(buf.String(), "log level escalated") {
+		t.Errorf("escalation should be logged: %s", buf.String())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for levelVar.Level() != slog.LevelInfo && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if levelVar.Level() != slog.LevelInfo {
+		t.Fatalf("expected level to revert to INFO, got %v", levelVar.Level())
+	}
+	waitFor(t, func() bool { return strings.Contains(buf.String(), "reason=expired") })
+}
+
+func TestSetLevelFor_Cancel(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &lockedBuffer{}
+	MustInit(WithWriter(buf), WithLevel("WARN"))
+
+	_, _ = SetLevelFor("INFO", time.Hour)
+	cancel, _ := SetLevelFor("DEBUG", time.Hour)
+	cancel()
+	if levelVar.Level() != slog.LevelWarn {
+		t.Errorf("cancel should restore original level, got %v", levelVar.Level())
+	}
+	if !strings.Contains(buf.String(), "reason=cancelled") {
+		t.Errorf("cancel should be logged: %s", buf.String())
+	}
+
+	cancel, _ = SetLevelFor("DEBUG", time.Hour)
+	SetLevel("ERROR")
+	cancel()
+	if levelVar.Level() != slog.LevelError {
+		t.Errorf("explicit SetLevel should win over pending revert, got %v", levelVar.Level())
+	}
+}
+
+func TestSetLevelFor_UnknownLevel(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	MustInit(WithWriter(&lockedBuffer{}), WithLevel("ERROR"))
+	cancel, err := SetLevelFor("DEBGU", time.Hour)
+	if err == nil {
+		t.Error("unknown level should be rejected")
+	}
+	cancel()
+	if levelVar.Level() != slog.LevelError {
+		t.Errorf("unknown level should leave the level unchanged, got %v", levelVar.Level())
+	}
+}
+
+func TestLevelHandler_Duration(t *testing.T) {
+	defer func() { _ = Close() }()
+	defer SetLevel("INFO")
+
+	MustInit(WithLevel("INFO"))
+	h := LevelHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"level":"DEBUG","duration":"1h"}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var p levelPayload
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if p.Level != "DEBUG" || p.Until == nil {
+		t.Errorf("response should report escalation: %+v", p)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/level", strings.NewReader(`{"duration":"soon"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestLevelName(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{LevelTrace, "TRACE"},
+		{slog.LevelInfo, "INFO"},
+		{LevelNotice, "NOTICE"},
+		{slog.LevelInfo + 1, "INFO+1"},
+		{LevelFatal + 2, "FATAL+2"},
+		{LevelTrace - 1, "TRACE-1"},
+	}
+	for _, tt := range tests {
+		if got := levelName(tt.level); got != tt.want {
+			t.Errorf("levelName(%d) = %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	const levelAudit = slog.Level(6)
+	RegisterLevel(levelAudit, "audit", fgBlue)
+	defer func() {
+		levelsMu.Lock()
+		delete(levelMap, "AUDIT")
+		delete(levelInfos, levelAudit)
+		levelsMu.Unlock()
+	}()
+
+	if lv, ok := lookupLevel("Audit"); !ok || lv != levelAudit {
+		t.Errorf("registered level should be parseable, got %v", lv)
+	}
+	if getLevelColor(levelAudit) != fgBlue {
+		t.Error("registered level should use its color")
+	}
+
+	for _, f := range []Formatter{JSON(), Text(), ColorText(), ColorJSON()} {
+		buf := &bytes.Buffer{}
+		slog.New(f.Format(buf, nil)).Log(context.Background(), levelAudit, "audit record")
+		if !strings.Contains(buf.String(), "AUDIT") {
+			t.Errorf("%T output should contain level name: %s", f, buf.String())
+		}
+	}
+}
+
+func TestRegisterLevel_Names(t *testing.T) {
+	RegisterLevel(-6, "super-debug", "")
+	RegisterLevel(7, "page", "")
+	RegisterLevel(9, "page", "")
+	defer func() {
+		levelsMu.Lock()
+		delete(levelMap, "SUPER-DEBUG")
+		delete(levelMap, "PAGE")
+		delete(levelInfos, -6)
+		delete(levelInfos, 9)
+		levelsMu.Unlock()
+	}()
+
+	for s, want := range map[string]slog.Level{"SUPER-DEBUG": -6, "super-debug+1": -5, "SUPER-DEBUG-2": -8, "PAGE": 9} {
+		if lv, err := ParseLevel(s); err != nil || lv != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", s, lv, err, want)
+		}
+	}
+	if name := levelName(7); name != "WARN+3" {
+		t.Errorf("re-registered name should leave its old level, got %q", name)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}), WithLevel("TRACE"), WithAddSource(true))
+	Trace("trace message")
+	Notice("notice message")
+
+	output := buf.String()
+	if !strings.Contains(output, "level=TRACE") || !strings.Contains(output, "level=NOTICE") {
+		t.Errorf("output should contain custom level names: %s", output)
+	}
+	if !strings.Contains(output, "level_test.go") {
+		t.Errorf("source should point to caller: %s", output)
+	}
+}
+
+func TestFatal(t *testing.T) {
+	defer func() { exitFunc = os.Exit }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}))
+
+	var code int
+	exitFunc = func(c int) { code = c }
+	Fatal("fatal message")
+	if code != 1 {
+		t.Errorf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(buf.String(), "level=FATAL") {
+		t.Errorf("output should contain FATAL: %s", buf.String())
+	}
+}
+
+func TestPanic(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}))
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Panic() should panic")
+		}
+		if !strings.Contains(buf.String(), "level=PANIC") {
+			t.Errorf("output should contain PANIC: %s", buf.String())
+		}
+	}()
+	Panic("panic message")
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+		ok   bool
+	}{
+		{"debug", slog.LevelDebug, true},
+		{"warning", slog.LevelWarn, true},
+		{"err", slog.LevelError, true},
+		{"fatal", LevelFatal, true},
+		{"Critical", LevelFatal, true},
+		{"-4", slog.LevelDebug, true},
+		{"6", slog.Level(6), true},
+		{"INFO+2", slog.Level(2), true},
+		{"error-1", slog.Level(7), true},
+		{"INFO ", slog.LevelInfo, false},
+		{"INFO+x", slog.LevelInfo, false},
+		{"", slog.LevelInfo, false},
+		{"verbose", slog.LevelInfo, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package s_log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"unicode/utf8"
+)
+
+// Limits bounds the size of records before they are formatted. Zero means
+// no limit. Shortened strings end with TruncationMarker and the number of
+// bytes removed is reported in a truncated_bytes attr.
+type Limits struct {
+	// MaxMessage is the maximum message length in bytes.
+	MaxMessage int `json:"max_message,omitempty" yaml:"max_message,omitempty"`
+	// MaxValue is the maximum length of string, []byte and fmt.Stringer
+	// values.
+	MaxValue int `json:"max_value,omitempty" yaml:"max_value,omitempty"`
+	// MaxAttrs is the maximum number of attrs, counting those bound with With.
+	MaxAttrs int `json:"max_attrs,omitempty" yaml:"max_attrs,omitempty"`
+	// MaxRecord is the maximum estimated size of the message plus all keys
+	// and values; attrs past the budget are shortened or dropped.
+	MaxRecord int `json:"max_record,omitempty" yaml:"max_record,omitempty"`
+}
+
+const (
+	TruncationMarker  = "...[truncated]"
+	TruncatedBytesKey = "truncated_bytes"
+)
+
+func (l Limits) validate() error {
+	if l.MaxMessage < 0 || l.MaxValue < 0 || l.MaxAttrs < 0 || l.MaxRecord < 0 {
+		return fmt.Errorf("s_log: invalid limits %+v", l)
+	}
+	return nil
+}
+
+// WithLimits applies l to every sink that does not set its own with Limit.
+func WithLimits(l Limits) Option {
+	return func(c *config) { c.limits = l }
+}
+
+type limitWriter struct {
+	Writer
+	limits Limits
+}
+
+// Limit gives w its own limits. Inside Multi, each limited sink gets a
+// handler of its own so the other sinks still receive the full record.
+func Limit(w Writer, l Limits) Writer {
+	return &limitWriter{Writer: w, limits: l}
+}
+
+func (w *limitWriter) Flush() error { return flushWriter(w.Writer) }
+
+func (w *limitWriter) file() *os.File {
+	if fb, ok := w.Writer.(fileBacked); ok {
+		return fb.file()
+	}
+	return nil
+}
+
+func (w *limitWriter) validate() error {
+	var errs []error
+	if w.Writer == nil {
+		return errors.New("s_log: nil writer in Limit")
+	}
+	if v, ok := w.Writer.(validator); ok {
+		errs = append(errs, v.validate())
+	}
+	return errors.Join(append(errs, w.limits.validate())...)
+}
+
+// sinkHandlers formats to cfg.w, splitting a Multi into one handler per sink
+// when some of its sinks carry their own limits.
+func (c *config) sinkHandlers(opts *slog.HandlerOptions) slog.Handler {
+	mw, ok := c.w.(*multiWriter)
+	if !ok || !slices.ContainsFunc(mw.writers, func(w Writer) bool { _, ok := w.(*limitWriter); return ok }) {
+		return c.sinkHandler(c.w, opts)
+	}
+	hs := make(fanoutHandler, len(mw.writers))
+	for i, w := range mw.writers {
+		hs[i] = c.sinkHandler(w, opts)
+	}
+	return hs
+}
+
+func (c *config) sinkHandler(w Writer, opts *slog.HandlerOptions) slog.Handler {
+	limits := c.limits
+	if lw, ok := w.(*limitWriter); ok {
+		limits = lw.limits
+	}
+	h := c.fmt.Format(w, opts)
+	if limits != (Limits{}) {
+		h = &limitHandler{Handler: h, limits: limits}
+	}
+	return h
+}
+
+type fanoutHandler []slog.Handler
+
+func (hs fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return slices.ContainsFunc(hs, func(h slog.Handler) bool { return h.Enabled(ctx, level) })
+}
+
+// Handle sends r to every sink. The sinks share one level, and records below
+// it may still arrive through named logger or vmodule overrides, so the level
+// is not checked again here.
+func (hs fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range hs {
+		errs = append(errs, h.Handle(ctx, r.Clone()))
+	}
+	return errors.Join(errs...)
+}
+
+func (hs fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanoutHandler, len(hs))
+	for i, h := range hs {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (hs fanoutHandler) WithGroup(name string) slog.Handler {
+	out := make(fanoutHandler, len(hs))
+	for i, h := range hs {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+type limitHandler struct {
+	slog.Handler
+	limits Limits
+	// bound accounts for attrs already passed down with WithAttrs.
+	boundAttrs, boundSize, boundTruncated int
+}
+
+// limiter tracks the bytes removed from one record.
+type limiter struct {
+	limits    Limits
+	truncated int
+}
+
+func (l *limiter) truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	l.truncated += len(s) - n
+	return s[:n] + TruncationMarker
+}
+
+func (l *limiter) value(v slog.Value) slog.Value {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if s := v.String(); len(s) > l.limits.MaxValue {
+			return slog.StringValue(l.truncate(s, l.limits.MaxValue))
+		}
+	case slog.KindGroup:
+		attrs := v.Group()
+		out := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			out[i] = slog.Attr{Key: a.Key, Value: l.value(a.Value)}
+		}
+		return slog.GroupValue(out...)
+	case slog.KindAny:
+		var s string
+		switch x := v.Any().(type) {
+		case []byte:
+			s = string(x)
+		case error:
+			return v
+		case fmt.Stringer:
+			s = x.String()
+		default:
+			return v
+		}
+		if len(s) > l.limits.MaxValue {
+			return slog.StringValue(l.truncate(s, l.limits.MaxValue))
+		}
+	}
+	return v
+}
+
+func (l *limiter) attr(a slog.Attr) slog.Attr {
+	if l.limits.MaxValue > 0 {
+		a.Value = l.value(a.Value)
+	}
+	return a
+}
+
+// fit shortens or drops a so that it fits in budget bytes, and returns the
+// budget left.
+func (l *limiter) fit(a slog.Attr, budget int) (slog.Attr, int, bool) {
+	size := attrSize(a)
+	if size <= budget {
+		return a, budget - size, true
+	}
+	if a.Value.Kind() == slog.KindString && budget > len(a.Key) {
+		return slog.String(a.Key, l.truncate(a.Value.String(), budget-len(a.Key))), 0, true
+	}
+	l.truncated += size
+	return a, budget, false
+}
+
+func attrSize(a slog.Attr) int {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return len(a.Key) + len(v.String())
+	case slog.KindGroup:
+		n := len(a.Key)
+		for _, ga := range v.Group() {
+			n += attrSize(ga)
+		}
+		return n
+	}
+	if b, ok := v.Any().([]byte); ok {
+		return len(a.Key) + len(b)
+	}
+	return len(a.Key) + len(v.String())
+}
+
+func (h *limitHandler) Handle(ctx context.Context, r slog.Record) error {
+	l := limiter{limits: h.limits, truncated: h.boundTruncated}
+	msg := r.Message
+	if h.limits.MaxMessage > 0 {
+		msg = l.truncate(msg, h.limits.MaxMessage)
+	}
+	budget := h.limits.MaxRecord - len(msg) - h.boundSize
+	if h.limits.MaxRecord > 0 && budget < 0 {
+		msg = l.truncate(msg, max(h.limits.MaxRecord-h.boundSize, 0))
+		budget = 0
+	}
+	nr := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	n := h.boundAttrs
+	r.Attrs(func(a slog.Attr) bool {
+		a = l.attr(a)
+		if h.limits.MaxAttrs > 0 && n >= h.limits.MaxAttrs {
+			l.truncated += attrSize(a)
+			return true
+		}
+		if h.limits.MaxRecord > 0 {
+			var ok bool
+			if a, budget, ok = l.fit(a, budget); !ok {
+				return true
+			}
+		}
+		nr.AddAttrs(a)
+		n++
+		return true
+	})
+	if l.truncated > 0 {
+		nr.AddAttrs(slog.Int(TruncatedBytesKey, l.truncated))
+	}
+	return h.Handler.Handle(ctx, nr)
+}
+
+func (h *limitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	l := limiter{limits: h.limits}
+	kept := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a = l.attr(a)
+		if h.limits.MaxAttrs > 0 && h2.boundAttrs >= h.limits.MaxAttrs {
+			l.truncated += attrSize(a)
+			continue
+		}
+		kept = append(kept, a)
+		h2.boundAttrs++
+		h2.boundSize += attrSize(a)
+	}
+	h2.boundTruncated += l.truncated
+	h2.Handler = h.Handler.WithAttrs(kept)
+	return &h2
+}
+
+func (h *limitHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.Handler = h.Handler.WithGroup(name)
+	return &h2
+}
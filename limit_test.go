@@ -0,0 +1,108 @@
+package s_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func limitRecord(t *testing.T, limits Limits, log func(*Logger)) map[string]any {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	l, err := New(WithWriter(&testWriter{buf: buf}), WithFormatter(JSON()), WithLimits(limits))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log(l)
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid record %q: %v", buf.String(), err)
+	}
+	return entry
+}
+
+func TestLimits(t *testing.T) {
+	entry := limitRecord(t, Limits{MaxMessage: 5, MaxValue: 4}, func(l *Logger) {
+		l.With("bound", "abcdefgh").Info("hello world", "body", []byte("0123456789"), "n", 42, "short", "ok")
+	})
+	if entry["msg"] != "hello"+TruncationMarker {
+		t.Errorf("message should be truncated: %v", entry["msg"])
+	}
+	if entry["body"] != "0123"+TruncationMarker || entry["bound"] != "abcd"+TruncationMarker || entry["short"] != "ok" {
+		t.Errorf("values should be truncated: %v", entry)
+	}
+	if entry[TruncatedBytesKey] != float64(6+6+4) {
+		t.Errorf("truncated_bytes should count removed bytes, got %v", entry[TruncatedBytesKey])
+	}
+
+	entry = limitRecord(t, Limits{MaxAttrs: 2}, func(l *Logger) {
+		l.With("a", 1).Info("m", "b", 2, "c", 3)
+	})
+	if _, ok := entry["c"]; ok || entry["b"] != float64(2) || entry[TruncatedBytesKey] != float64(2) {
+		t.Errorf("attrs past MaxAttrs should be dropped: %v", entry)
+	}
+
+	entry = limitRecord(t, Limits{MaxRecord: 20}, func(l *Logger) {
+		l.Info("msg", "k", "vvvv", "body", strings.Repeat("x", 100), "after", 1)
+	})
+	if entry["k"] != "vvvv" || entry["body"] != "xxxxxxxx"+TruncationMarker || entry[TruncatedBytesKey] != float64(92+6) {
+		t.Errorf("value past the record budget should be shortened: %v", entry)
+	}
+	if _, ok := entry["after"]; ok {
+		t.Errorf("attrs past the record budget should be dropped: %v", entry)
+	}
+
+	entry = limitRecord(t, Limits{MaxValue: 100}, func(l *Logger) { l.Info("m", "k", "v") })
+	if _, ok := entry[TruncatedBytesKey]; ok {
+		t.Errorf("untouched records should not report truncation: %v", entry)
+	}
+}
+
+func TestLimit_PerSink(t *testing.T) {
+	full, limited := &bytes.Buffer{}, &bytes.Buffer{}
+	l, err := New(WithFormatter(JSON()), WithWriter(Multi(
+		&testWriter{buf: full},
+		Limit(&testWriter{buf: limited}, Limits{MaxValue: 3}),
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.With("req", "abcdef").Info("m", "body", "123456")
+	if !strings.Contains(full.String(), `"req":"abcdef","body":"123456"`) || strings.Contains(full.String(), TruncatedBytesKey) {
+		t.Errorf("unlimited sink should get the full record: %s", full.String())
+	}
+	if !strings.Contains(limited.String(), `"req":"abc...[truncated]","body":"123...[truncated]","truncated_bytes":6`) {
+		t.Errorf("limited sink should truncate: %s", limited.String())
+	}
+
+	if _, err := New(WithLimits(Limits{MaxValue: -1})); err == nil {
+		t.Error("negative limits should be rejected")
+	}
+}
+
+func TestLimit_PerSinkOverrides(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"named":   WithLoggerLevels("db=DEBUG"),
+		"vmodule": WithVModule("limit_test=DEBUG"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			limited, full := &bytes.Buffer{}, &bytes.Buffer{}
+			l, err := New(WithWriter(Multi(
+				Limit(&testWriter{buf: limited}, Limits{MaxValue: 10}),
+				&testWriter{buf: full},
+			)), WithLevel("INFO"), opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Named("db").Debug("x")
+			if !strings.Contains(limited.String(), "msg=x") || !strings.Contains(full.String(), "msg=x") {
+				t.Errorf("override should reach every sink: limited=%q full=%q", limited.String(), full.String())
+			}
+			l.Named("api").Debug("z")
+			if name == "named" && (strings.Contains(limited.String(), "msg=z") || strings.Contains(full.String(), "msg=z")) {
+				t.Errorf("records below the level should still be dropped: limited=%q full=%q", limited.String(), full.String())
+			}
+		})
+	}
+}
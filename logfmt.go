@@ -0,0 +1,241 @@
+package s_log
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type logfmtFormatter struct {
+	fo formatOptions
+}
+
+func (f *logfmtFormatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	return &logfmtHandler{w: w, mu: &sync.Mutex{}, opts: *withFormatOptions(opts, &f.fo), fo: &f.fo}
+}
+
+var logfmtFmt = &logfmtFormatter{}
+
+func Logfmt(opts ...FormatOption) Formatter {
+	if len(opts) == 0 {
+		return logfmtFmt
+	}
+	return &logfmtFormatter{fo: newFormatOptions(opts)}
+}
+
+type logfmtHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   slog.HandlerOptions
+	fo     *formatOptions
+	attrs  []slog.Attr
+	groups []string
+	prefix string
+}
+
+func (h *logfmtHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *logfmtHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make([]slog.Attr, 0, 4+len(h.attrs)+r.NumAttrs())
+	if !r.Time.IsZero() {
+		fields = h.appendBuiltin(fields, slog.Time(slog.TimeKey, r.Time))
+	}
+	fields = h.appendBuiltin(fields, slog.Any(slog.LevelKey, r.Level))
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		fields = h.appendBuiltin(fields, slog.Any(slog.SourceKey, &slog.Source{Function: f.Function, File: f.File, Line: f.Line}))
+	}
+	fields = h.appendBuiltin(fields, slog.String(slog.MessageKey, r.Message))
+	fields = append(fields, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = h.appendAttr(fields, h.prefix, h.groups, a)
+		return true
+	})
+	if len(h.fo.keyOrder) > 0 {
+		slices.SortStableFunc(fields, func(a, b slog.Attr) int {
+			return h.fo.keyRank(a.Key) - h.fo.keyRank(b.Key)
+		})
+	}
+
+	bp := getBuf()
+	buf := *bp
+	for i, a := range fields {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = appendLogfmtKey(buf, a.Key)
+		buf = append(buf, '=')
+		buf = h.appendValue(buf, a.Key, a.Value)
+	}
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	_, err := h.w.Write(buf)
+	h.mu.Unlock()
+	*bp = buf
+	putBuf(bp)
+	return err
+}
+
+func (h *logfmtHandler) appendBuiltin(fields []slog.Attr, a slog.Attr) []slog.Attr {
+	if h.opts.ReplaceAttr != nil {
+		if a = h.opts.ReplaceAttr(nil, a); a.Equal(slog.Attr{}) {
+			return fields
+		}
+	}
+	return append(fields, a)
+}
+
+func (h *logfmtHandler) appendAttr(fields []slog.Attr, prefix string, groups []string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		if h.opts.ReplaceAttr != nil {
+			a = h.opts.ReplaceAttr(groups, a)
+			a.Value = a.Value.Resolve()
+		}
+		if a.Equal(slog.Attr{}) {
+			return fields
+		}
+		a = h.fo.expandError(a)
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key != "" {
+			prefix += a.Key + "."
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range attrs {
+			fields = h.appendAttr(fields, prefix, groups, ga)
+		}
+		return fields
+	}
+	a.Key = prefix + a.Key
+	return append(fields, a)
+}
+
+func (h *logfmtHandler) appendValue(buf []byte, key string, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return appendLogfmtString(buf, v.String())
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.AppendFloat(buf, v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		if h.fo.durationUnit > 0 {
+			return strconv.AppendFloat(buf, float64(v.Duration())/float64(h.fo.durationUnit), 'f', -1, 64)
+		}
+		return append(buf, v.Duration().String()...)
+	case slog.KindTime:
+		if key == slog.TimeKey {
+			return v.Time().AppendFormat(buf, timeFormat)
+		}
+		return v.Time().AppendFormat(buf, time.RFC3339Nano)
+	case slog.KindGroup:
+		return appendLogfmtString(buf, fmt.Sprint(v.Group()))
+	}
+	switch x := v.Any().(type) {
+	case slog.Level:
+		return appendLogfmtString(buf, levelName(x))
+	case *slog.Source:
+		return appendLogfmtString(buf, x.File+":"+strconv.Itoa(x.Line))
+	case error:
+		return appendLogfmtString(buf, x.Error())
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			return appendLogfmtString(buf, string(b))
+		}
+	case []byte:
+		return appendLogfmtString(buf, string(x))
+	}
+	return appendLogfmtString(buf, fmt.Sprintf("%+v", v.Any()))
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == 0x7f || b == '\\' {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// appendLogfmtString quotes s when needed, using JSON escapes as logfmt
+// parsers expect rather than Go ones such as \x00.
+func appendLogfmtString(buf []byte, s string) []byte {
+	if needsQuoting(s) {
+		return appendJSONString(buf, s)
+	}
+	return append(buf, s...)
+}
+
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || r == utf8.RuneError || !unicode.IsPrint(r) {
+			buf = append(buf, '_')
+		} else {
+			buf = utf8.AppendRune(buf, r)
+		}
+	}
+	return buf
+}
+
+func (h *logfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = slices.Clip(h.attrs)
+	for _, a := range attrs {
+		h2.attrs = h.appendAttr(h2.attrs, h.prefix, h.groups, a)
+	}
+	return &h2
+}
+
+func (h *logfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(slices.Clip(h.groups), name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
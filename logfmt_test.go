@@ -0,0 +1,184 @@
+package s_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// parseLogfmt is a minimal strict logfmt parser used to check round-trips.
+func parseLogfmt(t *testing.T, line string) [][2]string {
+	t.Helper()
+	var pairs [][2]string
+	for line = strings.TrimSuffix(line, "\n"); line != ""; {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			t.Fatalf("missing key in %q", line)
+		}
+		key := line[:eq]
+		if strings.ContainsAny(key, " \"") {
+			t.Fatalf("invalid key %q", key)
+		}
+		line = line[eq+1:]
+		var val string
+		if strings.HasPrefix(line, `"`) {
+			end := 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				t.Fatalf("unterminated quoted value in %q", line)
+			}
+			// Quoted values follow JSON string rules, as in go-logfmt.
+			if err := json.Unmarshal([]byte(line[:end+1]), &val); err != nil {
+				t.Fatalf("bad quoted value in %q: %v", line, err)
+			}
+			line = line[end+1:]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			val = line[:end]
+			if strings.ContainsAny(val, "=\"") {
+				t.Fatalf("unquoted value %q contains reserved characters", val)
+			}
+			line = line[end:]
+		}
+		pairs = append(pairs, [2]string{key, val})
+		if line != "" {
+			if line[0] != ' ' {
+				t.Fatalf("expected separator in %q", line)
+			}
+			line = line[1:]
+		}
+	}
+	return pairs
+}
+
+func TestLogfmt_RoundTrip(t *testing.T) {
+	values := []string{"plain", "with space", "a=b", `say "hi"`, "line\nbreak", "", "tab\there", `back\slash`, "héllo", "\x00ctl"}
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(Logfmt().Format(buf, nil))
+	args := make([]any, 0, len(values)*2)
+	for i, v := range values {
+		args = append(args, "k"+strconv.Itoa(i), v)
+	}
+	logger.Info("msg with space", args...)
+
+	pairs := parseLogfmt(t, buf.String())
+	got := map[string]string{}
+	for _, p := range pairs {
+		got[p[0]] = p[1]
+	}
+	if got["msg"] != "msg with space" || got["level"] != "INFO" {
+		t.Errorf("unexpected builtins: %v", pairs)
+	}
+	for i, v := range values {
+		if got["k"+strconv.Itoa(i)] != v {
+			t.Errorf("value %q did not round-trip, got %q", v, got["k"+strconv.Itoa(i)])
+		}
+	}
+}
+
+func TestLogfmt_Escapes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(Logfmt().Format(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("m", "ctl", "a\x00b\x1f", "bad", "x\xffy")
+
+	want := `level=INFO msg=m ctl="a\u0000b\u001f" bad="x\ufffdy"` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+	if !utf8.Valid(buf.Bytes()) {
+		t.Errorf("output is not valid UTF-8: %q", buf.String())
+	}
+	got := map[string]string{}
+	for _, p := range parseLogfmt(t, buf.String()) {
+		got[p[0]] = p[1]
+	}
+	if got["ctl"] != "a\x00b\x1f" || got["bad"] != "x\ufffdy" {
+		t.Errorf("unexpected values: %q", got)
+	}
+}
+
+func TestLogfmt_KeysAndGroups(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(Logfmt().Format(buf, nil)).With("svc", "api").WithGroup("req")
+	logger.Info("hi", "bad key", 1, "a=b", true, slog.Group("user", "id", 7), "err", errors.New("boom x"))
+
+	pairs := parseLogfmt(t, buf.String())
+	want := [][2]string{{"svc", "api"}, {"req.bad_key", "1"}, {"req.a_b", "true"}, {"req.user.id", "7"}, {"req.err", "boom x"}}
+	if len(pairs) != 3+len(want) {
+		t.Fatalf("unexpected pairs: %v", pairs)
+	}
+	for i, w := range want {
+		if pairs[3+i] != w {
+			t.Errorf("pair %d = %v, want %v", i, pairs[3+i], w)
+		}
+	}
+}
+
+func TestLogfmt_Options(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := Logfmt(WithKeyOrder("level", "msg", "elapsed"), WithTimeFormat(time.Kitchen), WithDurationUnit(time.Millisecond))
+	if f == Logfmt() {
+		t.Error("Logfmt() with options should not return singleton")
+	}
+	logger := slog.New(f.Format(buf, nil))
+	logger.Info("done", "user", "u1", "elapsed", 1500*time.Microsecond)
+
+	pairs := parseLogfmt(t, buf.String())
+	keys := make([]string, len(pairs))
+	for i, p := range pairs {
+		keys[i] = p[0]
+	}
+	if strings.Join(keys, ",") != "level,msg,elapsed,time,user" {
+		t.Errorf("unexpected key order: %v", keys)
+	}
+	if pairs[2][1] != "1.5" {
+		t.Errorf("expected duration in ms, got %q", pairs[2][1])
+	}
+	if _, err := time.Parse(time.Kitchen, pairs[3][1]); err != nil {
+		t.Errorf("time should use custom layout: %q", pairs[3][1])
+	}
+}
+
+func TestLogfmt_ReplaceAttrAndSource(t *testing.T) {
+	buf := &bytes.Buffer{}
+	h := Logfmt().Format(buf, &slog.HandlerOptions{
+		AddSource: true,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "secret" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	slog.New(h).Warn("x", "secret", "pw", "keep", 1)
+
+	output := buf.String()
+	if strings.Contains(output, "time=") || strings.Contains(output, "secret") {
+		t.Errorf("ReplaceAttr should drop attrs: %s", output)
+	}
+	if !strings.Contains(output, "level=WARN source=") || !strings.Contains(output, "logfmt_test.go:") {
+		t.Errorf("output should contain source: %s", output)
+	}
+}
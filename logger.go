@@ -0,0 +1,100 @@
+package s_log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Logger struct {
+	*slog.Logger
+	level *slog.LevelVar
+	state atomic.Pointer[handlerState]
+	mu    sync.Mutex
+
+	levelMu    sync.Mutex
+	levelTimer *time.Timer
+	levelPrev  slog.Level
+	levelUntil time.Time
+	levelGen   uint64
+
+	namedMu sync.Mutex
+	named   map[string]*loggerLevel
+}
+
+func newLogger(level *slog.LevelVar) *Logger {
+	l := &Logger{level: level, named: map[string]*loggerLevel{}}
+	l.Logger = slog.New(&swapHandler{src: &l.state})
+	return l
+}
+
+func New(opts ...Option) (*Logger, error) {
+	cfg := newConfig(opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	cfg.build()
+	l := newLogger(new(slog.LevelVar))
+	l.apply(cfg)
+	return l, nil
+}
+
+func (l *Logger) apply(cfg *config) {
+	l.mu.Lock()
+
+	l.setLevel(cfg.level)
+	_ = l.SetLoggerLevels(cfg.loggerLevels)
+	h := cfg.sinkHandlers(&slog.HandlerOptions{
+		Level:     l.level,
+		AddSource: cfg.addSource,
+	})
+
+	if cfg.dedup != DedupNone {
+		h = &dedupHandler{Handler: h, mode: cfg.dedup}
+	}
+	if cfg.interceptor != nil {
+		h = &handlerWrapper{Handler: h, interceptor: cfg.interceptor}
+	}
+	if cfg.stackLevel != nil {
+		h = &stackHandler{Handler: h, level: *cfg.stackLevel}
+	}
+	if cfg.vmodule != "" {
+		if vm, _ := parseVModule(cfg.vmodule); len(vm.rules) > 0 {
+			h = &vmoduleHandler{Handler: h, vm: vm}
+		}
+	}
+
+	old := l.state.Swap(&handlerState{h: h, w: cfg.w, owned: cfg.sinks != nil || !cfg.keepWriter})
+	l.mu.Unlock()
+
+	if old != nil {
+		_ = old.retire(old.owned && old.w != cfg.w)
+	}
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if st := l.state.Load(); st != nil {
+		return st.retire(true)
+	}
+	return nil
+}
+
+// Flush waits until records already handed to the writer are written,
+// without closing it.
+func (l *Logger) Flush() error {
+	if st := l.state.Load(); st != nil {
+		return flushWriter(st.w)
+	}
+	return nil
+}
+
+func (l *Logger) FromContext(ctx context.Context) *slog.Logger {
+	if requestID, ok := ctx.Value(contextKey{}).(string); ok {
+		return l.With("request_id", requestID)
+	}
+	return l.Logger
+}
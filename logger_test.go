@@ -0,0 +1,84 @@
+package s_log
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	buf := &lockedBuffer{}
+	l, err := New(WithWriter(buf), WithLevel("DEBUG"), WithFormatter(JSON()))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	l.Debug("instance message", "k", "v")
+	if !strings.Contains(buf.String(), `"msg":"instance message","k":"v"`) {
+		t.Errorf("instance should write to its own writer: %s", buf.String())
+	}
+	if slog.Default().Handler() == l.Handler() {
+		t.Error("New() should not install the default logger")
+	}
+}
+
+func TestNew_Independent(t *testing.T) {
+	t.Parallel()
+
+	buf1, buf2 := &lockedBuffer{}, &lockedBuffer{}
+	l1, err := New(WithWriter(buf1), WithLevel("INFO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2, err := New(WithWriter(buf2), WithLevel("ERROR"), WithLoggerLevels("db=DEBUG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l1.SetLevel("DEBUG")
+	if l2.Level() != slog.LevelError {
+		t.Errorf("SetLevel on one instance should not affect another, got %v", l2.Level())
+	}
+	if len(l1.LoggerLevels()) != 0 || l2.LoggerLevels()["db"] != "DEBUG" {
+		t.Error("named overrides should be per instance")
+	}
+
+	l2.Named("db").Debug("db debug")
+	l2.FromContext(WithRequestID(context.Background(), "r1")).Error("with request")
+	if !strings.Contains(buf2.String(), "db debug") || !strings.Contains(buf2.String(), "request_id=r1") {
+		t.Errorf("unexpected output: %s", buf2.String())
+	}
+	if buf1.String() != "" {
+		t.Errorf("other instance should not receive records: %s", buf1.String())
+	}
+
+	w := &closeCounter{}
+	l3, err := New(WithWriter(w))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l3.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	if w.closed != 1 {
+		t.Errorf("Close() should close the instance writer once, got %d", w.closed)
+	}
+	_ = l1.Close()
+	_ = l2.Close()
+}
+
+func TestNew_Invalid(t *testing.T) {
+	t.Parallel()
+
+	l, err := New(WithLevel("LOUD"), WithWriter(nil))
+	if err == nil || l != nil {
+		t.Fatal("New() should fail on invalid options")
+	}
+	if !strings.Contains(err.Error(), "LOUD") || !strings.Contains(err.Error(), "nil writer") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
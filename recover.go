@@ -0,0 +1,99 @@
+package s_log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type recoverOptions struct {
+	repanic bool
+}
+
+type RecoverOption func(*recoverOptions)
+
+// WithRepanic re-raises the recovered value after it is logged, closing the
+// logger first since the process is expected to crash.
+func WithRepanic() RecoverOption {
+	return func(o *recoverOptions) { o.repanic = true }
+}
+
+// Recover logs a panic in progress at LevelFatal with its stack and flushes
+// the writer. It must be deferred directly: defer s_log.Recover(ctx).
+func Recover(ctx context.Context, opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		std.handlePanic(ctx, v, opts)
+	}
+}
+
+// Recover is the Logger counterpart of the package level Recover.
+func (l *Logger) Recover(ctx context.Context, opts ...RecoverOption) {
+	if v := recover(); v != nil {
+		l.handlePanic(ctx, v, opts)
+	}
+}
+
+// Go runs fn in a new goroutine that recovers and logs its panics.
+func Go(fn func(), opts ...RecoverOption) {
+	go func() {
+		defer Recover(context.Background(), opts...)
+		fn()
+	}()
+}
+
+func (l *Logger) Go(fn func(), opts ...RecoverOption) {
+	go func() {
+		defer l.Recover(context.Background(), opts...)
+		fn()
+	}()
+}
+
+func (l *Logger) handlePanic(ctx context.Context, v any, opts []RecoverOption) {
+	var o recoverOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	pcs := panicStack(callers(3))
+	if l.Enabled(ctx, LevelFatal) {
+		var pc uintptr
+		if len(pcs) > 0 {
+			pc = pcs[0]
+		}
+		r := slog.NewRecord(time.Now(), LevelFatal, "panic recovered", pc)
+		if err, ok := v.(error); ok {
+			r.AddAttrs(slog.Any("error", err))
+		} else {
+			r.AddAttrs(slog.String("panic", fmt.Sprint(v)))
+		}
+		r.AddAttrs(slog.Any("stack", stackOf(pcs)))
+		_ = l.Handler().Handle(ctx, r)
+	}
+	if o.repanic {
+		_ = l.Close()
+		panic(v)
+	}
+	_ = l.Flush()
+}
+
+// panicStack drops the frames of the deferred call and the runtime's panic
+// machinery so the stack starts where the panic was raised.
+func panicStack(pcs []uintptr) []uintptr {
+	for i := len(pcs) - 1; i >= 0; i-- {
+		if f := frameFunc(pcs[i]); f == "runtime.gopanic" || f == "runtime.sigpanic" {
+			pcs = pcs[i+1:]
+			for len(pcs) > 1 && strings.HasPrefix(frameFunc(pcs[0]), "runtime.") {
+				pcs = pcs[1:]
+			}
+			return pcs
+		}
+	}
+	return pcs
+}
+
+func frameFunc(pc uintptr) string {
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return f.Function
+}
@@ -0,0 +1,103 @@
+package s_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func recoverLogger(t *testing.T, w Writer) *Logger {
+	t.Helper()
+	l, err := New(WithWriter(w), WithFormatter(JSON()), WithLevel("INFO"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func panicky() {
+	var m map[string]int
+	m["boom"]++
+}
+
+func TestRecover_LogsAndFlushes(t *testing.T) {
+	buf := &lockedBuffer{}
+	l := recoverLogger(t, Async(buf, 16))
+	defer func() { _ = l.Close() }()
+
+	func() {
+		defer l.Recover(context.Background())
+		panicky()
+	}()
+
+	var entry struct {
+		Level string  `json:"level"`
+		Msg   string  `json:"msg"`
+		Error string  `json:"error"`
+		Stack []Frame `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &entry); err != nil {
+		t.Fatalf("record should be flushed before Recover returns: %v (%q)", err, buf.String())
+	}
+	if entry.Level != "FATAL" || entry.Msg != "panic recovered" || !strings.Contains(entry.Error, "nil map") {
+		t.Errorf("unexpected record: %+v", entry)
+	}
+	if len(entry.Stack) == 0 || !strings.HasSuffix(entry.Stack[0].Function, ".panicky") {
+		t.Errorf("stack should start at the panicking function, got %+v", entry.Stack)
+	}
+}
+
+func TestRecover_Repanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := recoverLogger(t, &testWriter{buf: buf})
+
+	var got any
+	func() {
+		defer func() { got = recover() }()
+		defer l.Recover(context.Background(), WithRepanic())
+		panic("again")
+	}()
+	if got != "again" {
+		t.Errorf("expected the original value to be re-raised, got %v", got)
+	}
+	if !strings.Contains(buf.String(), `"panic":"again"`) {
+		t.Errorf("panic should be logged before re-raising: %s", buf.String())
+	}
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := recoverLogger(t, &testWriter{buf: buf})
+	func() {
+		defer l.Recover(context.Background())
+	}()
+	if buf.Len() != 0 {
+		t.Errorf("nothing should be logged without a panic: %s", buf.String())
+	}
+}
+
+func TestGo(t *testing.T) {
+	buf := &lockedBuffer{}
+	l := recoverLogger(t, buf)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	l.Go(func() {
+		defer wg.Done()
+		panic(errors.New("worker failed"))
+	})
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(buf.String(), "worker failed") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.Contains(buf.String(), `"error":"worker failed"`) {
+		t.Errorf("goroutine panic should be logged: %s", buf.String())
+	}
+}
@@ -2,17 +2,19 @@ package s_log
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
-	"strings"
-	"sync"
+	"os"
 	"time"
 )
 
+var exitFunc = os.Exit
+
 var (
-	globalLogger *slog.Logger
-	globalWriter Writer
 	levelVar     slog.LevelVar
-	mu           sync.RWMutex
+	std          = newLogger(&levelVar)
+	globalLogger = std.Logger
 )
 
 type Interceptor func(ctx context.Context, r *Record) *Record
@@ -22,16 +24,26 @@ type Record struct {
 	Level   slog.Level
 	Message string
 	Attrs   []slog.Attr
+	// PC is the program counter of the logging call, used for the source.
+	PC uintptr
 }
 
 type Option func(*config)
 
 type config struct {
-	level       slog.Level
-	fmt         Formatter
-	w           Writer
-	addSource   bool
-	interceptor Interceptor
+	level        slog.Level
+	fmt          Formatter
+	w            Writer
+	addSource    bool
+	interceptor  Interceptor
+	loggerLevels string
+	vmodule      string
+	stackLevel   *slog.Level
+	dedup        DedupMode
+	limits       Limits
+	sinks        []SinkConfig
+	keepWriter   bool
+	errs         []error
 }
 
 type contextKey struct{}
@@ -50,6 +62,7 @@ func (h *handlerWrapper) Handle(ctx context.Context, r slog.Record) error {
 		Level:   r.Level,
 		Message: r.Message,
 		Attrs:   make([]slog.Attr, 0, r.NumAttrs()),
+		PC:      r.PC,
 	}
 	r.Attrs(func(a slog.Attr) bool {
 		rec.Attrs = append(rec.Attrs, a)
@@ -58,23 +71,49 @@ func (h *handlerWrapper) Handle(ctx context.Context, r slog.Record) error {
 	if rec = h.interceptor(ctx, rec); rec == nil {
 		return nil
 	}
-	nr := slog.NewRecord(rec.Time, rec.Level, rec.Message, 0)
+	nr := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
 	for _, a := range rec.Attrs {
 		nr.AddAttrs(a)
 	}
 	return h.Handler.Handle(ctx, nr)
 }
 
+func (h *handlerWrapper) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handlerWrapper{Handler: h.Handler.WithAttrs(attrs), interceptor: h.interceptor}
+}
+
+func (h *handlerWrapper) WithGroup(name string) slog.Handler {
+	return &handlerWrapper{Handler: h.Handler.WithGroup(name), interceptor: h.interceptor}
+}
+
 func WithLevel(level string) Option {
-	return func(c *config) { c.level = parseLevel(level) }
+	return func(c *config) {
+		lv, err := ParseLevel(level)
+		if err != nil {
+			c.errs = append(c.errs, err)
+		}
+		c.level = lv
+	}
 }
 
 func WithFormatter(f Formatter) Option {
-	return func(c *config) { c.fmt = f }
+	return func(c *config) {
+		if f == nil {
+			c.errs = append(c.errs, errors.New("s_log: nil formatter"))
+			return
+		}
+		c.fmt = f
+	}
 }
 
 func WithWriter(w Writer) Option {
-	return func(c *config) { c.w = w }
+	return func(c *config) {
+		if w == nil {
+			c.errs = append(c.errs, errors.New("s_log: nil writer"))
+			return
+		}
+		c.w, c.sinks = w, nil
+	}
 }
 
 func WithAddSource(on bool) Option {
@@ -85,28 +124,19 @@ func WithInterceptor(interceptor Interceptor) Option {
 	return func(c *config) { c.interceptor = interceptor }
 }
 
-var levelMap = map[string]slog.Level{
-	"DEBUG": slog.LevelDebug,
-	"INFO":  slog.LevelInfo,
-	"WARN":  slog.LevelWarn,
-	"ERROR": slog.LevelError,
+func errOption(err error) Option {
+	return func(c *config) { c.errs = append(c.errs, err) }
 }
 
-func parseLevel(s string) slog.Level {
-	if lv, ok := levelMap[strings.ToUpper(s)]; ok {
-		return lv
-	}
-	return slog.LevelInfo
+func WithLoggerLevels(spec string) Option {
+	return func(c *config) { c.loggerLevels = spec }
 }
 
-func MustInit(opts ...Option) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if globalWriter != nil {
-		_ = globalWriter.Close()
-	}
+func WithVModule(spec string) Option {
+	return func(c *config) { c.vmodule = spec }
+}
 
+func newConfig(opts []Option) *config {
 	cfg := &config{
 		level:     slog.LevelInfo,
 		fmt:       Text(),
@@ -116,39 +146,84 @@ func MustInit(opts ...Option) {
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	return cfg
+}
 
-	levelVar.Set(cfg.level)
-	h := cfg.fmt.Format(cfg.w, &slog.HandlerOptions{
-		Level:     &levelVar,
-		AddSource: cfg.addSource,
-	})
+func (c *config) validate() error {
+	errs := c.errs
+	if c.loggerLevels != "" {
+		if _, err := parseLoggerLevels(c.loggerLevels); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.vmodule != "" {
+		if _, err := parseVModule(c.vmodule); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := c.limits.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for i, sc := range c.sinks {
+		if err := sc.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("s_log: sink %d: %w", i, err))
+		}
+	}
+	if v, ok := c.w.(validator); ok && c.sinks == nil {
+		if err := v.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
 
-	if cfg.interceptor != nil {
-		h = &handlerWrapper{Handler: h, interceptor: cfg.interceptor}
+// build creates the writers that were deferred until validation passed.
+func (c *config) build() {
+	if c.sinks != nil {
+		c.w = c.sinksWriter()
 	}
+}
 
-	globalLogger = slog.New(h)
+// Init configures the global logger. LOG_LEVELS provides the per-logger
+// levels unless WithLoggerLevels is given; loggers made with New ignore it.
+func Init(opts ...Option) error {
+	cfg := newConfig(append([]Option{WithLoggerLevels(os.Getenv(EnvLoggerLevels))}, opts...))
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	cfg.build()
+	install(cfg)
+	return nil
+}
+
+// MustInit is like Init but panics if the options are invalid.
+func MustInit(opts ...Option) {
+	if err := Init(opts...); err != nil {
+		panic(err)
+	}
+}
+
+func install(cfg *config) {
+	std.apply(cfg)
 	slog.SetDefault(globalLogger)
-	globalWriter = cfg.w
 }
 
 func Close() error {
-	mu.Lock()
-	defer mu.Unlock()
-	if globalWriter != nil {
-		return globalWriter.Close()
-	}
-	return nil
+	return std.Close()
 }
 
-func SetLevel(level string) {
-	levelVar.Set(parseLevel(level))
+func Flush() error {
+	return std.Flush()
+}
+
+func SetLevel(level string) error {
+	return std.SetLevel(level)
 }
 
 func PresetDev() []Option {
 	return []Option{
 		WithLevel("DEBUG"),
-		WithFormatter(ColorText()),
+		WithFormatter(Auto()),
 		WithWriter(Stdout()),
 		WithAddSource(true),
 	}
@@ -173,12 +248,9 @@ func Preset(level, format, file string) []Option {
 
 	opts := []Option{WithLevel(level)}
 
-	switch strings.ToLower(format) {
-	case "text":
-		opts = append(opts, WithFormatter(Text()))
-	case "color":
-		opts = append(opts, WithFormatter(ColorText()))
-	default:
+	if f, ok := formatterByName(format); ok {
+		opts = append(opts, WithFormatter(f))
+	} else {
 		opts = append(opts, WithFormatter(JSON()))
 	}
 
@@ -195,8 +267,28 @@ func WithRequestID(ctx context.Context, requestID string) context.Context {
 }
 
 func FromContext(ctx context.Context) *slog.Logger {
-	if requestID, ok := ctx.Value(contextKey{}).(string); ok {
-		return globalLogger.With("request_id", requestID)
-	}
-	return globalLogger
+	return std.FromContext(ctx)
+}
+
+func logAt(ctx context.Context, level slog.Level, msg string, args ...any) {
+	std.logSkip(ctx, 1, level, msg, args...)
+}
+
+func Trace(msg string, args ...any) {
+	logAt(context.Background(), LevelTrace, msg, args...)
+}
+
+func Notice(msg string, args ...any) {
+	logAt(context.Background(), LevelNotice, msg, args...)
+}
+
+func Fatal(msg string, args ...any) {
+	logAt(context.Background(), LevelFatal, msg, args...)
+	_ = Close()
+	exitFunc(1)
+}
+
+func Panic(msg string, args ...any) {
+	logAt(context.Background(), LevelPanic, msg, args...)
+	panic(msg)
 }
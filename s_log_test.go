@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"log/slog"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -18,6 +20,43 @@ func TestInit(t *testing.T) {
 	}
 }
 
+func TestInit_Validation(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	if err := Init(WithLevel("warning"), WithWriter(&testWriter{buf: &bytes.Buffer{}})); err != nil {
+		t.Fatalf("Init() failed: %v", err)
+	}
+	if levelVar.Level() != slog.LevelWarn {
+		t.Errorf("expected WARN, got %v", levelVar.Level())
+	}
+
+	tmpDir := t.TempDir()
+	blocker := filepath.Join(tmpDir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := Init(
+		WithLevel("INFO "),
+		WithFormatter(nil),
+		WithWriter(Multi(
+			File(filepath.Join(blocker, "app.log")),
+			File(filepath.Join(tmpDir, "app.log"), WithRotation(0, 3)),
+		)),
+		WithLoggerLevels("db=LOUD"),
+	)
+	if err == nil {
+		t.Fatal("Init() should fail on invalid options")
+	}
+	for _, want := range []string{`"INFO "`, "nil formatter", "not writable", "rotation size", `"LOUD"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %s: %v", want, err)
+		}
+	}
+	if levelVar.Level() != slog.LevelWarn {
+		t.Error("failed Init() should keep previous configuration")
+	}
+}
+
 func TestMustInit(t *testing.T) {
 	defer func() { _ = Close() }()
 
@@ -48,7 +87,6 @@ func TestWithLevel(t *testing.T) {
 		{"WARN", "WARN", slog.LevelWarn},
 		{"ERROR", "ERROR", slog.LevelError},
 		{"lowercase", "debug", slog.LevelDebug},
-		{"invalid", "INVALID", slog.LevelInfo},
 	}
 
 	for _, tt := range tests {
@@ -61,6 +99,23 @@ func TestWithLevel(t *testing.T) {
 	}
 }
 
+func TestMustInit_InvalidLevel(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	MustInit(WithLevel("WARN"))
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("MustInit should panic on an unknown level")
+			}
+		}()
+		MustInit(WithLevel("INVALID"))
+	}()
+	if levelVar.Level() != slog.LevelWarn {
+		t.Errorf("rejected options should not be applied, got %v", levelVar.Level())
+	}
+}
+
 func TestWithFormatter(t *testing.T) {
 	defer func() { _ = Close() }()
 
@@ -112,6 +167,13 @@ func TestSetLevel(t *testing.T) {
 	if levelVar.Level() != slog.LevelError {
 		t.Errorf("expected ERROR, got %v", levelVar.Level())
 	}
+
+	if err := SetLevel("warning "); err == nil {
+		t.Error("SetLevel should reject unknown levels")
+	}
+	if levelVar.Level() != slog.LevelError {
+		t.Errorf("unknown level should leave the level unchanged, got %v", levelVar.Level())
+	}
 }
 
 func TestPresetDev(t *testing.T) {
@@ -194,6 +256,21 @@ func TestWithInterceptor(t *testing.T) {
 	}
 }
 
+func TestWithInterceptor_DerivedLoggers(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &lockedBuffer{}
+	MustInit(WithWriter(buf), WithInterceptor(func(ctx context.Context, r *Record) *Record {
+		r.Attrs = append(r.Attrs, slog.String("env", "test"))
+		return r
+	}))
+
+	slog.With("svc", "api").WithGroup("g").Info("derived")
+	if !strings.Contains(buf.String(), "svc=api g.env=test") {
+		t.Errorf("interceptor should apply to derived loggers: %s", buf.String())
+	}
+}
+
 func TestWithInterceptor_Filter(t *testing.T) {
 	defer func() { _ = Close() }()
 
@@ -276,3 +353,24 @@ func (w *testWriter) Write(p []byte) (int, error) {
 func (w *testWriter) Close() error {
 	return nil
 }
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *lockedBuffer) Close() error {
+	return nil
+}
@@ -0,0 +1,179 @@
+package s_log
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+type schema struct {
+	timeKey  string
+	levelKey string
+	msgKey   string
+	level    func(slog.Level) string
+	source   func(*slog.Source) slog.Attr
+	err      func(err error) slog.Attr
+	attrs    []slog.Attr
+}
+
+type schemaFormatter struct {
+	s  *schema
+	fo formatOptions
+}
+
+func (f *schemaFormatter) Format(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	o := slog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	old := o.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if old != nil {
+			a = old(groups, a)
+		}
+		if len(groups) > 0 {
+			return f.fo.expandError(a)
+		}
+		return f.fo.expandError(f.replace(a))
+	}
+	h := slog.Handler(slog.NewJSONHandler(w, &o))
+	if len(f.s.attrs) > 0 {
+		h = h.WithAttrs(f.s.attrs)
+	}
+	return h
+}
+
+func (f *schemaFormatter) replace(a slog.Attr) slog.Attr {
+	switch a.Key {
+	case slog.TimeKey:
+		if a.Value.Kind() != slog.KindTime {
+			break
+		}
+		if f.fo.omitTime {
+			return slog.Attr{}
+		}
+		return slog.Attr{Key: f.s.timeKey, Value: f.fo.timeValue(a.Value.Time())}
+	case slog.LevelKey:
+		if lv, ok := a.Value.Any().(slog.Level); ok {
+			return slog.String(f.s.levelKey, f.s.level(lv))
+		}
+	case slog.MessageKey:
+		return slog.Attr{Key: f.s.msgKey, Value: a.Value}
+	case slog.SourceKey:
+		if src, ok := a.Value.Any().(*slog.Source); ok && f.s.source != nil {
+			s := *src
+			s.File = f.fo.sourcePath(src.File, src.Function, SourceFull)
+			return f.s.source(&s)
+		}
+		return f.fo.sourceAttr(a)
+	case "error", "err":
+		if err, ok := a.Value.Any().(error); ok && f.s.err != nil {
+			return f.s.err(err)
+		}
+	}
+	return a
+}
+
+// errorStack returns the stack captured in err's chain, or the "%+v"
+// rendering of err when it carries more than its message.
+func errorStack(err error) string {
+	if stack := stackOf(errorPCs(err)); len(stack) > 0 {
+		return stack.String()
+	}
+	if s := fmt.Sprintf("%+v", err); s != err.Error() {
+		return s
+	}
+	return ""
+}
+
+func errorGroup(err error, msgKey, typeKey, stackKey string) slog.Attr {
+	attrs := []any{slog.String(msgKey, err.Error()), slog.String(typeKey, fmt.Sprintf("%T", err))}
+	if stack := errorStack(err); stack != "" {
+		attrs = append(attrs, slog.String(stackKey, stack))
+	}
+	return slog.Group("error", attrs...)
+}
+
+const ecsVersion = "8.11.0"
+
+var ecsSchema = &schema{
+	timeKey:  "@timestamp",
+	levelKey: "log.level",
+	msgKey:   "message",
+	level:    func(lv slog.Level) string { return strings.ToLower(levelName(lv)) },
+	source: func(src *slog.Source) slog.Attr {
+		return slog.Group("log.origin",
+			slog.Group("file", slog.String("name", src.File), slog.Int("line", src.Line)),
+			slog.String("function", src.Function))
+	},
+	err: func(err error) slog.Attr {
+		return errorGroup(err, "message", "type", "stack_trace")
+	},
+	attrs: []slog.Attr{slog.String("ecs.version", ecsVersion)},
+}
+
+var gcpSchema = &schema{
+	timeKey:  "timestamp",
+	levelKey: "severity",
+	msgKey:   "message",
+	level:    gcpSeverity,
+	source: func(src *slog.Source) slog.Attr {
+		return slog.Group("logging.googleapis.com/sourceLocation",
+			slog.String("file", src.File), slog.String("line", strconv.Itoa(src.Line)), slog.String("function", src.Function))
+	},
+}
+
+func gcpSeverity(lv slog.Level) string {
+	switch {
+	case lv >= LevelPanic:
+		return "ALERT"
+	case lv >= LevelFatal:
+		return "CRITICAL"
+	case lv >= slog.LevelError:
+		return "ERROR"
+	case lv >= slog.LevelWarn:
+		return "WARNING"
+	case lv >= LevelNotice:
+		return "NOTICE"
+	case lv >= slog.LevelInfo:
+		return "INFO"
+	}
+	return "DEBUG"
+}
+
+var datadogSchema = &schema{
+	timeKey:  "timestamp",
+	levelKey: "status",
+	msgKey:   "message",
+	level:    func(lv slog.Level) string { return strings.ToLower(gcpSeverity(lv)) },
+	err: func(err error) slog.Attr {
+		return errorGroup(err, "message", "kind", "stack")
+	},
+}
+
+var (
+	ecsFmt     = &schemaFormatter{s: ecsSchema}
+	gcpFmt     = &schemaFormatter{s: gcpSchema}
+	datadogFmt = &schemaFormatter{s: datadogSchema}
+)
+
+func (f *schemaFormatter) with(opts []FormatOption) Formatter {
+	if len(opts) == 0 {
+		return f
+	}
+	return &schemaFormatter{s: f.s, fo: newFormatOptions(opts)}
+}
+
+// ECS writes Elastic Common Schema fields: @timestamp, log.level, message,
+// log.origin and error.{message,type,stack_trace}.
+func ECS(opts ...FormatOption) Formatter { return ecsFmt.with(opts) }
+
+// GCP writes Google Cloud Logging fields: timestamp, severity, message and
+// logging.googleapis.com/sourceLocation.
+func GCP(opts ...FormatOption) Formatter { return gcpFmt.with(opts) }
+
+// Datadog writes Datadog reserved attributes: timestamp, status, message and
+// error.{message,kind,stack}.
+func Datadog(opts ...FormatOption) Formatter { return datadogFmt.with(opts) }
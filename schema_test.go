@@ -0,0 +1,102 @@
+package s_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+type stackErr struct{ msg string }
+
+func (e *stackErr) Error() string { return e.msg }
+
+func (e *stackErr) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, e.msg)
+	if s.Flag('+') {
+		fmt.Fprint(s, "\nmain.run\n\tmain.go:10")
+	}
+}
+
+func decodeSchema(t *testing.T, f Formatter, log func(*slog.Logger)) map[string]any {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	log(slog.New(f.Format(buf, &slog.HandlerOptions{AddSource: true, Level: LevelTrace})))
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestECS(t *testing.T) {
+	m := decodeSchema(t, ECS(), func(l *slog.Logger) {
+		l.Warn("disk full", "error", &stackErr{"boom"}, "path", "/data")
+	})
+	if m["message"] != "disk full" || m["log.level"] != "warn" || m["ecs.version"] != ecsVersion || m["path"] != "/data" {
+		t.Errorf("unexpected ECS fields: %v", m)
+	}
+	if _, ok := m["@timestamp"]; !ok {
+		t.Errorf("missing @timestamp: %v", m)
+	}
+	origin, _ := m["log.origin"].(map[string]any)
+	if file, _ := origin["file"].(map[string]any); file["name"] == nil || file["line"] == nil {
+		t.Errorf("missing log.origin.file: %v", m)
+	}
+	e, _ := m["error"].(map[string]any)
+	if e["message"] != "boom" || e["type"] != "*s_log.stackErr" || e["stack_trace"] == nil {
+		t.Errorf("unexpected error fields: %v", m["error"])
+	}
+}
+
+func TestGCP(t *testing.T) {
+	levels := map[slog.Level]string{
+		LevelTrace: "DEBUG", slog.LevelDebug: "DEBUG", slog.LevelInfo: "INFO", LevelNotice: "NOTICE",
+		slog.LevelWarn: "WARNING", slog.LevelError: "ERROR", LevelFatal: "CRITICAL", LevelPanic: "ALERT",
+	}
+	for lv, want := range levels {
+		m := decodeSchema(t, GCP(), func(l *slog.Logger) { l.Log(context.Background(), lv, "m") })
+		if m["severity"] != want {
+			t.Errorf("level %v: severity = %v, want %s", lv, m["severity"], want)
+		}
+	}
+
+	m := decodeSchema(t, GCP(WithoutTime()), func(l *slog.Logger) { l.Info("hello", "error", errors.New("x")) })
+	if _, ok := m["timestamp"]; ok {
+		t.Errorf("timestamp should be omitted: %v", m)
+	}
+	loc, _ := m["logging.googleapis.com/sourceLocation"].(map[string]any)
+	if _, ok := loc["line"].(string); !ok || loc["file"] == nil || m["message"] != "hello" || m["error"] != "x" {
+		t.Errorf("unexpected GCP fields: %v", m)
+	}
+}
+
+func TestDatadog(t *testing.T) {
+	m := decodeSchema(t, Datadog(), func(l *slog.Logger) {
+		l.Error("failed", "err", errors.New("boom"), slog.Group("g", "error", errors.New("nested")))
+	})
+	if m["status"] != "error" || m["message"] != "failed" || m["timestamp"] == nil {
+		t.Errorf("unexpected Datadog fields: %v", m)
+	}
+	e, _ := m["error"].(map[string]any)
+	if e["message"] != "boom" || e["kind"] != "*errors.errorString" || e["stack"] != nil {
+		t.Errorf("unexpected error fields: %v", m["error"])
+	}
+	if g, _ := m["g"].(map[string]any); g["error"] != "nested" {
+		t.Errorf("grouped errors should be left alone: %v", m["g"])
+	}
+}
+
+func TestSchema_ByName(t *testing.T) {
+	for _, name := range []string{"ecs", "gcp", "datadog"} {
+		if _, ok := formatterByName(name); !ok {
+			t.Errorf("formatter %q should be registered", name)
+		}
+	}
+	if ECS() != ECS() || ECS(WithUTC()) == ECS() {
+		t.Error("ECS() should return a singleton only without options")
+	}
+}
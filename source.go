@@ -0,0 +1,166 @@
+package s_log
+
+import (
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SourceStyle selects how the file of the source location is printed.
+type SourceStyle int
+
+const (
+	// SourceDefault keeps each formatter's own style: ColorText and Console
+	// print paths relative to the working directory, the others full paths.
+	SourceDefault SourceStyle = iota
+	SourceFull
+	// SourceRelative prints paths relative to the working directory, and
+	// files from the module cache as module@version/file.go.
+	SourceRelative
+	// SourceModule prints the import path of the package followed by the
+	// file name, relative to the main module.
+	SourceModule
+	SourceBase
+)
+
+func WithSourceStyle(style SourceStyle) FormatOption {
+	return func(o *formatOptions) { o.sourceStyle = style }
+}
+
+// WithSourceFunction appends the package qualified function name to the
+// source location of text formats. JSON formats always include it.
+func WithSourceFunction() FormatOption {
+	return func(o *formatOptions) { o.sourceFunc = true }
+}
+
+func (o *formatOptions) sourcePath(file, function string, def SourceStyle) string {
+	style := o.sourceStyle
+	if style == SourceDefault {
+		style = def
+	}
+	switch style {
+	case SourceRelative:
+		if trimmed, ok := trimModCache(file); ok {
+			return trimmed
+		}
+		return relativePath(file)
+	case SourceModule:
+		return modulePath(file, function)
+	case SourceBase:
+		return filepath.Base(file)
+	}
+	return file
+}
+
+// sourceText renders src as "file:line", followed by the function name when
+// WithSourceFunction is set.
+func (o *formatOptions) sourceText(src *slog.Source, def SourceStyle) string {
+	s := o.sourcePath(src.File, src.Function, def) + ":" + strconv.Itoa(src.Line)
+	if o.sourceFunc && src.Function != "" {
+		s += " " + shortFunction(src.Function)
+	}
+	return s
+}
+
+// sourceAttr applies the source options to the source attribute of
+// formatters that print full paths by default.
+func (o *formatOptions) sourceAttr(a slog.Attr) slog.Attr {
+	if o.sourceStyle == SourceDefault && !o.sourceFunc {
+		return a
+	}
+	src, ok := a.Value.Any().(*slog.Source)
+	if !ok {
+		return a
+	}
+	s := *src
+	s.File = o.sourcePath(src.File, src.Function, SourceFull)
+	if o.sourceFunc {
+		a.Value = slog.AnyValue((*funcSource)(&s))
+	} else {
+		a.Value = slog.AnyValue(&s)
+	}
+	return a
+}
+
+// funcSource is a source that text handlers print with its function name.
+type funcSource slog.Source
+
+func (s *funcSource) MarshalText() ([]byte, error) {
+	text := s.File + ":" + strconv.Itoa(s.Line)
+	if s.Function != "" {
+		text += " " + shortFunction(s.Function)
+	}
+	return []byte(text), nil
+}
+
+func (s *funcSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*slog.Source)(s))
+}
+
+func shortFunction(function string) string {
+	return function[strings.LastIndexByte(function, '/')+1:]
+}
+
+const modCacheDir = "/pkg/mod/"
+
+func trimModCache(file string) (string, bool) {
+	if i := strings.Index(file, modCacheDir); i >= 0 {
+		return file[i+len(modCacheDir):], true
+	}
+	return file, false
+}
+
+var buildInfo = sync.OnceValues(func() (mainPkg, mainModule string) {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Path, bi.Main.Path
+	}
+	return "", ""
+})
+
+// modulePath returns the import path of the package that defines function
+// joined with the file name, dropping the main module prefix.
+func modulePath(file, function string) string {
+	pkg := packagePath(file, function)
+	if pkg == "" {
+		if trimmed, ok := trimModCache(file); ok {
+			return trimmed
+		}
+		return relativePath(file)
+	}
+	mainPkg, mainModule := buildInfo()
+	if pkg == "main" && mainPkg != "" {
+		pkg = mainPkg
+	}
+	base := filepath.Base(file)
+	switch {
+	case mainModule == "":
+	case pkg == mainModule:
+		return base
+	case strings.HasPrefix(pkg, mainModule+"/"):
+		pkg = pkg[len(mainModule)+1:]
+	}
+	return pkg + "/" + base
+}
+
+// packagePath extracts the package path from a function name. The last
+// path element may itself contain dots (gopkg.in/yaml.v3), so it is matched
+// against the directory of file first.
+func packagePath(file, function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	rest := function[slash+1:]
+	dir := filepath.Base(filepath.Dir(file))
+	if i := strings.IndexByte(dir, '@'); i >= 0 {
+		dir = dir[:i]
+	}
+	if strings.HasPrefix(rest, dir+".") {
+		return function[:slash+1+len(dir)]
+	}
+	if dot := strings.IndexByte(rest, '.'); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package s_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSourceStyle_AllFormatters(t *testing.T) {
+	formatters := map[string]func(...FormatOption) Formatter{
+		"json": JSON, "text": Text, "logfmt": Logfmt, "fastjson": FastJSON,
+		"color": ColorText, "console": Console, "ecs": ECS, "gcp": GCP, "datadog": Datadog,
+	}
+	styles := []struct {
+		style SourceStyle
+		want  string
+	}{
+		{SourceBase, "source_test.go:"},
+		{SourceRelative, "source_test.go:"},
+		{SourceModule, "source_test.go:"},
+	}
+	for name, f := range formatters {
+		for _, s := range styles {
+			buf := &bytes.Buffer{}
+			h := f(WithSourceStyle(s.style), WithColor(ColorNever)).Format(buf, &slog.HandlerOptions{AddSource: true})
+			slog.New(h).Info("hello")
+			out := strings.ReplaceAll(buf.String(), `"line":`, ":")
+			if strings.Contains(out, "/root/") || strings.Contains(out, "s_log/source_test.go") || !strings.Contains(out, "source_test.go") {
+				t.Errorf("%s with style %d: %s", name, s.style, buf.String())
+			}
+		}
+	}
+}
+
+func TestSourceStyle_Defaults(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slog.New(JSON().Format(buf, &slog.HandlerOptions{AddSource: true})).Info("hello")
+	var entry struct {
+		Source slog.Source `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(entry.Source.File, "/") {
+		t.Errorf("JSON should keep the full path by default, got %q", entry.Source.File)
+	}
+
+	buf.Reset()
+	slog.New(ColorText(WithColor(ColorNever)).Format(buf, &slog.HandlerOptions{AddSource: true})).Info("hello")
+	if !strings.Contains(buf.String(), `source="source_test.go:`) {
+		t.Errorf("ColorText should print a cwd-relative path by default: %s", buf.String())
+	}
+}
+
+func TestWithSourceFunction(t *testing.T) {
+	for name, f := range map[string]Formatter{
+		"text":    Text(WithSourceStyle(SourceBase), WithSourceFunction()),
+		"logfmt":  Logfmt(WithSourceStyle(SourceBase), WithSourceFunction()),
+		"console": Console(WithSourceFunction(), WithColor(ColorNever)),
+	} {
+		buf := &bytes.Buffer{}
+		slog.New(f.Format(buf, &slog.HandlerOptions{AddSource: true})).Info("hello")
+		if !strings.Contains(buf.String(), " s_log.TestWithSourceFunction") {
+			t.Errorf("%s should print the function name: %s", name, buf.String())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	slog.New(JSON(WithSourceStyle(SourceBase), WithSourceFunction()).Format(buf, &slog.HandlerOptions{AddSource: true})).Info("hello")
+	var entry struct {
+		Source slog.Source `json:"source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Source.File != "source_test.go" || !strings.HasSuffix(entry.Source.Function, ".TestWithSourceFunction") {
+		t.Errorf("JSON should keep the source group, got %+v", entry.Source)
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	tests := []struct{ file, function, want string }{
+		{"/home/u/go/pkg/mod/gopkg.in/yaml.v3@v3.0.1/decode.go", "gopkg.in/yaml.v3.(*decoder).unmarshal", "gopkg.in/yaml.v3/decode.go"},
+		{"/home/u/go/pkg/mod/github.com/a/b/v2@v2.1.0/c.go", "github.com/a/b/v2.Run", "github.com/a/b/v2/c.go"},
+		{"/usr/local/go/src/net/http/server.go", "net/http.(*conn).serve", "net/http/server.go"},
+		{"/src/s_log/sub/x.go", pkgPath + "/sub.F[...]", "sub/x.go"},
+		{"/src/s_log/x.go", pkgPath + ".F", "x.go"},
+		{"/home/u/go/pkg/mod/github.com/a/b@v1.0.0/c.go", "", "github.com/a/b@v1.0.0/c.go"},
+	}
+	for _, tt := range tests {
+		if got := modulePath(tt.file, tt.function); got != tt.want {
+			t.Errorf("modulePath(%q, %q) = %q, want %q", tt.file, tt.function, got, tt.want)
+		}
+	}
+}
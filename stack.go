@@ -0,0 +1,133 @@
+package s_log
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+type Stack []Frame
+
+func (s Stack) String() string {
+	var b strings.Builder
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+	}
+	return b.String()
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, 64)
+	return pcs[:runtime.Callers(skip+1, pcs)]
+}
+
+func stackOf(pcs []uintptr) Stack {
+	if len(pcs) == 0 {
+		return nil
+	}
+	stack := make(Stack, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "runtime.goexit" {
+			stack = append(stack, Frame{Function: f.Function, File: relativePath(f.File), Line: f.Line})
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+var workDir struct {
+	sync.Mutex
+	dir string
+}
+
+// relativePath returns file relative to the working directory when that is
+// shorter, so terminals and editors can open it.
+func relativePath(file string) string {
+	workDir.Lock()
+	if workDir.dir == "" {
+		workDir.dir, _ = os.Getwd()
+	}
+	wd := workDir.dir
+	workDir.Unlock()
+	if wd != "" {
+		if rel, err := filepath.Rel(wd, file); err == nil && len(rel) < len(file) {
+			return rel
+		}
+	}
+	return file
+}
+
+// WithStacktrace attaches the goroutine stack as a "stack" attr to records
+// at or above level.
+func WithStacktrace(level slog.Level) Option {
+	return func(c *config) { c.stackLevel = &level }
+}
+
+type stackHandler struct {
+	slog.Handler
+	level slog.Level
+}
+
+func (h *stackHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.level {
+		r = r.Clone()
+		r.AddAttrs(slog.Any("stack", stackOf(callerStack(r.PC))))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// callerStack returns the current stack starting at the frame that logged
+// pc, dropping slog and s_log frames when pc is unknown.
+func callerStack(pc uintptr) []uintptr {
+	pcs := callers(2)
+	for i, p := range pcs {
+		if p == pc {
+			return pcs[i:]
+		}
+	}
+	for i := range pcs {
+		if f, _ := runtime.CallersFrames(pcs[i : i+1]).Next(); !isLoggingFrame(f) {
+			return pcs[i:]
+		}
+	}
+	return nil
+}
+
+func isLoggingFrame(f runtime.Frame) bool {
+	if strings.HasPrefix(f.Function, "log/slog.") {
+		return true
+	}
+	return strings.HasPrefix(f.Function, pkgPath+".") && !strings.HasSuffix(f.File, "_test.go")
+}
+
+var pkgPath = reflect.TypeOf(Frame{}).PkgPath()
+
+func (h *stackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stackHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *stackHandler) WithGroup(name string) slog.Handler {
+	return &stackHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
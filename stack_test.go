@@ -0,0 +1,80 @@
+package s_log
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithStacktrace(t *testing.T) {
+	buf := &lockedBuffer{}
+	l, err := New(WithWriter(buf), WithFormatter(JSON()), WithStacktrace(slog.LevelError))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	l.Warn("no stack")
+	l.With("k", "v").Error("with stack")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || strings.Contains(lines[0], `"stack"`) {
+		t.Fatalf("records below the threshold should not carry a stack: %s", buf.String())
+	}
+	var m struct {
+		Stack []Frame `json:"stack"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Stack) == 0 || !strings.HasSuffix(m.Stack[0].Function, "TestWithStacktrace") {
+		t.Fatalf("stack should start at the logging call: %+v", m.Stack)
+	}
+	if m.Stack[0].File != "stack_test.go" {
+		t.Errorf("stack paths should be relative to the working directory: %q", m.Stack[0].File)
+	}
+}
+
+func TestWithStacktrace_ColorText(t *testing.T) {
+	buf := &lockedBuffer{}
+	l, err := New(WithWriter(buf), WithFormatter(ColorText(WithColor(ColorNever))), WithStacktrace(slog.LevelWarn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	l.Warn("boom", "k", 1)
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0], "boom k=1") || lines[1] != "    stack:" || !strings.Contains(lines[2], "TestWithStacktrace_ColorText") {
+		t.Errorf("stack should be printed on indented lines: %q", buf.String())
+	}
+}
+
+func TestStackHandler_UnknownPC(t *testing.T) {
+	var got Stack
+	h := &stackHandler{Handler: recordFunc(func(r slog.Record) {
+		r.Attrs(func(a slog.Attr) bool {
+			got, _ = a.Value.Any().(Stack)
+			return true
+		})
+	}), level: slog.LevelError}
+	_ = h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "m", 0))
+	if len(got) == 0 || !strings.HasSuffix(got[0].Function, "TestStackHandler_UnknownPC") {
+		t.Errorf("s_log frames should be trimmed: %v", got)
+	}
+}
+
+type recordFunc func(slog.Record)
+
+func (f recordFunc) Enabled(context.Context, slog.Level) bool { return true }
+
+func (f recordFunc) Handle(_ context.Context, r slog.Record) error {
+	f(r)
+	return nil
+}
+
+func (f recordFunc) WithAttrs([]slog.Attr) slog.Handler { return f }
+func (f recordFunc) WithGroup(string) slog.Handler      { return f }
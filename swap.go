@@ -0,0 +1,128 @@
+package s_log
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+type handlerState struct {
+	h slog.Handler
+	w Writer
+	// owned reports whether a reload may close w. Writers passed to Watch
+	// are reused by every reload and stay open.
+	owned   bool
+	mu      sync.RWMutex
+	retired bool
+}
+
+// retire blocks until in-flight records are written, then closes the
+// writer if closeWriter is set.
+func (st *handlerState) retire(closeWriter bool) error {
+	st.mu.Lock()
+	if st.retired {
+		st.mu.Unlock()
+		return nil
+	}
+	st.retired = true
+	st.mu.Unlock()
+	if !closeWriter {
+		return nil
+	}
+	return st.w.Close()
+}
+
+var fallbackHandler = slog.Default().Handler()
+
+type swapCache struct {
+	st *handlerState
+	h  slog.Handler
+}
+
+type swapHandler struct {
+	src   *atomic.Pointer[handlerState]
+	ops   []func(slog.Handler) slog.Handler
+	cache atomic.Pointer[swapCache]
+	// skip is the number of extra frames set by WithCallerSkip.
+	skip int
+}
+
+func (h *swapHandler) resolve(st *handlerState) slog.Handler {
+	if len(h.ops) == 0 {
+		return st.h
+	}
+	if c := h.cache.Load(); c != nil && c.st == st {
+		return c.h
+	}
+	hh := st.h
+	for _, op := range h.ops {
+		hh = op(hh)
+	}
+	h.cache.Store(&swapCache{st: st, h: hh})
+	return hh
+}
+
+func (h *swapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	st := h.src.Load()
+	if st == nil {
+		return h.fallback().Enabled(ctx, level)
+	}
+	return h.resolve(st).Enabled(ctx, level)
+}
+
+func (h *swapHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.PC != 0 && (h.skip > 0 || helpers.active.Load() && isHelper(r.PC)) {
+		r.PC = callerPC(r.PC, h.skip)
+	}
+	for {
+		st := h.src.Load()
+		if st == nil {
+			return h.fallback().Handle(ctx, r)
+		}
+		st.mu.RLock()
+		if !st.retired {
+			err := h.resolve(st).Handle(ctx, r)
+			st.mu.RUnlock()
+			return err
+		}
+		st.mu.RUnlock()
+		if h.src.Load() == st {
+			// Closed and not replaced: keep records visible on stderr. The
+			// slog default handler would loop back here through log.Printf.
+			return h.fallbackTo(slog.NewTextHandler(os.Stderr, nil)).Handle(ctx, r)
+		}
+	}
+}
+
+func (h *swapHandler) fallback() slog.Handler {
+	return h.fallbackTo(fallbackHandler)
+}
+
+func (h *swapHandler) fallbackTo(hh slog.Handler) slog.Handler {
+	for _, op := range h.ops {
+		hh = op(hh)
+	}
+	return hh
+}
+
+func (h *swapHandler) with(op func(slog.Handler) slog.Handler) *swapHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &swapHandler{src: h.src, ops: append(ops, op), skip: h.skip}
+}
+
+func (h *swapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(func(hh slog.Handler) slog.Handler { return hh.WithAttrs(attrs) })
+}
+
+func (h *swapHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(func(hh slog.Handler) slog.Handler { return hh.WithGroup(name) })
+}
@@ -0,0 +1,103 @@
+package s_log
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSwap_OldReferencesFollow(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	first := &lockedBuffer{}
+	MustInit(WithWriter(first))
+	logger := FromContext(WithRequestID(context.Background(), "req-1")).WithGroup("g")
+	named := Named("db")
+
+	second := &lockedBuffer{}
+	MustInit(WithWriter(second), WithFormatter(JSON()))
+	logger.Info("after swap", "k", "v")
+	named.Info("named after swap")
+
+	if strings.Contains(first.String(), "after swap") {
+		t.Error("old writer should not receive records after swap")
+	}
+	output := second.String()
+	if !strings.Contains(output, `"request_id":"req-1","g":{"k":"v"}`) {
+		t.Errorf("derived logger should keep attrs and groups: %s", output)
+	}
+	if !strings.Contains(output, "named after swap") {
+		t.Errorf("named logger should follow swap: %s", output)
+	}
+}
+
+func TestSwap_ConcurrentInit(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	MustInit(WithWriter(&lockedBuffer{}))
+	logger := FromContext(WithRequestID(context.Background(), "req"))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				logger.Info("concurrent", "n", 1)
+				slog.Debug("concurrent debug")
+			}
+		}()
+	}
+
+	writers := make([]*closeCounter, 20)
+	for i := range writers {
+		writers[i] = &closeCounter{}
+		MustInit(WithWriter(Async(writers[i], 16)), WithFormatter(JSON()))
+	}
+	close(stop)
+	wg.Wait()
+
+	for _, w := range writers[:len(writers)-1] {
+		if w.closed != 1 {
+			t.Errorf("retired writer should be closed once, got %d", w.closed)
+		}
+	}
+}
+
+func TestSwap_AfterClose(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	buf := &lockedBuffer{}
+	MustInit(WithWriter(buf))
+	logger := slog.With("k", "v")
+	_ = Close()
+
+	logger.Info("after close")
+	log.Print("from log package")
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	if strings.Contains(buf.String(), "after close") {
+		t.Error("closed writer should not receive records")
+	}
+	if !strings.Contains(string(out), "msg=\"after close\" k=v") || !strings.Contains(string(out), "from log package") {
+		t.Errorf("records after Close should go to stderr: %q", out)
+	}
+}
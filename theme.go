@@ -0,0 +1,159 @@
+package s_log
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Theme holds the ANSI sequences used by ColorText and ColorJSON. Empty
+// entries are printed without color. Levels missing from Levels use the
+// color registered for the level, in bold.
+type Theme struct {
+	Levels    map[slog.Level]string
+	Timestamp string
+	Message   string
+	Key       string
+	Values    map[slog.Kind]string
+	Error     string
+	Source    string
+	// Keys overrides the value color for attributes with the given key.
+	Keys map[string]string
+}
+
+func Color256(n uint8) string {
+	return fmt.Sprintf("\x1b[38;5;%dm", n)
+}
+
+func RGB(r, g, b uint8) string {
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b)
+}
+
+func Bold(color string) string {
+	return bold + color
+}
+
+func WithTheme(t *Theme) FormatOption {
+	return func(o *formatOptions) { o.theme = t }
+}
+
+func allKinds(color string) map[slog.Kind]string {
+	return map[slog.Kind]string{
+		slog.KindString: color, slog.KindInt64: color, slog.KindUint64: color, slog.KindFloat64: color,
+		slog.KindBool: color, slog.KindDuration: color, slog.KindTime: color, slog.KindAny: color,
+	}
+}
+
+func DarkTheme() *Theme {
+	values := allKinds(fgYellow)
+	values[slog.KindString] = fgGreen
+	values[slog.KindBool] = fgMagenta
+	values[slog.KindTime] = fgCyan
+	values[slog.KindAny] = fgGray
+	return &Theme{
+		Timestamp: fgGray,
+		Message:   fgCyan,
+		Key:       fgBlue,
+		Values:    values,
+		Error:     fgRed,
+		Source:    fgGray,
+	}
+}
+
+func LightTheme() *Theme {
+	values := allKinds(Color256(24))
+	values[slog.KindString] = Color256(22)
+	values[slog.KindBool] = fgMagenta
+	return &Theme{
+		Levels: map[slog.Level]string{
+			slog.LevelDebug: Bold(Color256(244)),
+			slog.LevelInfo:  Bold(Color256(28)),
+			slog.LevelWarn:  Bold(Color256(130)),
+			slog.LevelError: Bold(Color256(160)),
+		},
+		Timestamp: Color256(244),
+		Message:   Color256(17),
+		Key:       Color256(90),
+		Values:    values,
+		Error:     Color256(160),
+		Source:    Color256(244),
+	}
+}
+
+func HighContrastTheme() *Theme {
+	return &Theme{
+		Levels: map[slog.Level]string{
+			slog.LevelDebug: "\x1b[1;30;47m",
+			slog.LevelInfo:  "\x1b[1;30;42m",
+			slog.LevelWarn:  "\x1b[1;30;43m",
+			slog.LevelError: "\x1b[1;97;41m",
+		},
+		Timestamp: "\x1b[97m",
+		Message:   "\x1b[1;97m",
+		Key:       "\x1b[96m",
+		Values:    allKinds("\x1b[93m"),
+		Error:     "\x1b[1;91m",
+		Source:    "\x1b[37m",
+	}
+}
+
+var defaultTheme = DarkTheme()
+
+// appendLevel writes the level color sequence for lv.
+func (t *Theme) appendLevel(buf []byte, lv slog.Level) []byte {
+	if c, ok := t.Levels[lv]; ok {
+		return append(buf, c...)
+	}
+	return append(append(buf, bold...), getLevelColor(lv)...)
+}
+
+func (t *Theme) valueColor(key string, v slog.Value) string {
+	if c, ok := t.Keys[key]; ok {
+		return c
+	}
+	if v.Kind() == slog.KindAny {
+		if _, ok := v.Any().(error); ok {
+			return t.Error
+		}
+	}
+	return t.Values[v.Kind()]
+}
+
+// activeTheme returns the theme to use for w, or nil when color is off.
+func (o *formatOptions) activeTheme(w io.Writer) *Theme {
+	if !o.colorEnabled(w) {
+		return nil
+	}
+	if o.theme != nil {
+		return o.theme
+	}
+	return defaultTheme
+}
+
+func (t *Theme) timestamp() string {
+	if t == nil {
+		return ""
+	}
+	return t.Timestamp
+}
+
+func (t *Theme) message() string {
+	if t == nil {
+		return ""
+	}
+	return t.Message
+}
+
+func (t *Theme) key() string {
+	if t == nil {
+		return ""
+	}
+	return t.Key
+}
+
+func (t *Theme) source() string {
+	if t == nil {
+		return ""
+	}
+	return t.Source
+}
@@ -0,0 +1,73 @@
+package s_log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestColorHelpers(t *testing.T) {
+	if Color256(208) != "\x1b[38;5;208m" {
+		t.Errorf("unexpected 256-color sequence: %q", Color256(208))
+	}
+	if RGB(1, 2, 3) != "\x1b[38;2;1;2;3m" {
+		t.Errorf("unexpected truecolor sequence: %q", RGB(1, 2, 3))
+	}
+	if Bold(fgRed) != bold+fgRed {
+		t.Errorf("unexpected bold sequence: %q", Bold(fgRed))
+	}
+}
+
+func TestWithTheme(t *testing.T) {
+	theme := &Theme{
+		Levels:  map[slog.Level]string{slog.LevelInfo: RGB(0, 255, 0)},
+		Message: Color256(15),
+		Key:     Color256(33),
+		Values:  map[slog.Kind]string{slog.KindInt64: Color256(208)},
+		Error:   Color256(196),
+		Keys:    map[string]string{"user": Color256(201)},
+	}
+
+	buf := &bytes.Buffer{}
+	slog.New(ColorText(WithTheme(theme), WithoutTime()).Format(buf, nil)).Info("hi", "n", 1, "user", "bob", "err", errors.New("boom"), "s", "plain")
+	for _, want := range []string{
+		"level=" + RGB(0, 255, 0) + "INFO" + reset,
+		Color256(15) + "hi" + reset,
+		Color256(33) + "n" + reset + "=" + Color256(208) + "1" + reset,
+		Color256(201) + "bob" + reset,
+		Color256(196) + "boom" + reset,
+		"=plain",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("ColorText output should contain %q: %q", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	slog.New(ColorJSON(WithTheme(theme)).Format(buf, nil)).Warn("hi", "user", "bob", "n", 2)
+	for _, want := range []string{
+		Color256(33) + `"user"` + reset, Color256(201) + `"bob"` + reset, bold + fgYellow + `"WARN"` + reset,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("ColorJSON output should contain %q: %q", want, buf.String())
+		}
+	}
+}
+
+func TestBuiltinThemes(t *testing.T) {
+	for name, theme := range map[string]*Theme{"dark": DarkTheme(), "light": LightTheme(), "high-contrast": HighContrastTheme()} {
+		if theme.Message == "" || theme.Key == "" || theme.Error == "" || theme.Values[slog.KindString] == "" {
+			t.Errorf("%s theme should define the main colors", name)
+		}
+		buf := &bytes.Buffer{}
+		slog.New(ColorText(WithTheme(theme)).Format(buf, nil)).Error("x", "k", "v")
+		if !strings.Contains(buf.String(), theme.Message+"x"+reset) {
+			t.Errorf("%s theme not applied: %q", name, buf.String())
+		}
+	}
+	if DarkTheme() == DarkTheme() {
+		t.Error("theme constructors should return independent copies")
+	}
+}
@@ -0,0 +1,135 @@
+package s_log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+type vmoduleRule struct {
+	pattern string
+	level   slog.Level
+}
+
+type vmoduleResult struct {
+	level slog.Level
+	ok    bool
+}
+
+type vmodule struct {
+	rules    []vmoduleRule
+	minLevel slog.Level
+	cache    sync.Map
+}
+
+func parseVModule(spec string) (*vmodule, error) {
+	vm := &vmodule{}
+	var errs []error
+	for _, item := range strings.Split(spec, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		pattern, level, ok := strings.Cut(item, "=")
+		pattern, level = strings.TrimSpace(pattern), strings.TrimSpace(level)
+		if !ok || pattern == "" {
+			errs = append(errs, fmt.Errorf("s_log: invalid vmodule rule %q, want pattern=LEVEL", item))
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("s_log: invalid vmodule pattern %q: %w", pattern, err))
+			continue
+		}
+		lv, ok := lookupLevel(level)
+		if !ok {
+			errs = append(errs, fmt.Errorf("s_log: unknown level %q for vmodule pattern %q", level, pattern))
+			continue
+		}
+		if len(vm.rules) == 0 || lv < vm.minLevel {
+			vm.minLevel = lv
+		}
+		vm.rules = append(vm.rules, vmoduleRule{pattern: strings.TrimSuffix(pattern, ".go"), level: lv})
+	}
+	return vm, errors.Join(errs...)
+}
+
+func (vm *vmodule) lookup(pc uintptr) (slog.Level, bool) {
+	if v, ok := vm.cache.Load(pc); ok {
+		res := v.(vmoduleResult)
+		return res.level, res.ok
+	}
+	var res vmoduleResult
+	if f, _ := runtime.CallersFrames([]uintptr{pc}).Next(); f.File != "" {
+		file := strings.TrimSuffix(filepath.ToSlash(f.File), ".go")
+		pkg := funcPackage(f.Function)
+		for _, rule := range vm.rules {
+			if matchSegments(rule.pattern, file) || matchSegments(rule.pattern, pkg) {
+				res = vmoduleResult{level: rule.level, ok: true}
+				break
+			}
+		}
+	}
+	vm.cache.Store(pc, res)
+	return res.level, res.ok
+}
+
+// matchSegments reports whether pattern matches any run of consecutive
+// slash-separated segments of name.
+func matchSegments(pattern, name string) bool {
+	if name == "" {
+		return false
+	}
+	segs := strings.Split(strings.Trim(name, "/"), "/")
+	n := strings.Count(pattern, "/") + 1
+	for i := 0; i+n <= len(segs); i++ {
+		if ok, _ := path.Match(pattern, strings.Join(segs[i:i+n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func funcPackage(fn string) string {
+	slash := strings.LastIndex(fn, "/")
+	if dot := strings.Index(fn[slash+1:], "."); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
+
+type vmoduleHandler struct {
+	slog.Handler
+	vm *vmodule
+}
+
+func (h *vmoduleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.vm.minLevel || h.Handler.Enabled(ctx, level)
+}
+
+func (h *vmoduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.PC != 0 {
+		if lv, ok := h.vm.lookup(r.PC); ok {
+			if r.Level < lv {
+				return nil
+			}
+			return h.Handler.Handle(ctx, r)
+		}
+	}
+	if ctx.Value(levelOverrideKey{}) == nil && !h.Handler.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *vmoduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &vmoduleHandler{Handler: h.Handler.WithAttrs(attrs), vm: h.vm}
+}
+
+func (h *vmoduleHandler) WithGroup(name string) slog.Handler {
+	return &vmoduleHandler{Handler: h.Handler.WithGroup(name), vm: h.vm}
+}
@@ -0,0 +1,86 @@
+package s_log
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithVModule(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}), WithLevel("INFO"), WithVModule("vmodule_test=DEBUG"))
+	slog.Debug("debug from test file")
+	if !strings.Contains(buf.String(), "debug from test file") {
+		t.Error("vmodule rule should enable debug for matching file")
+	}
+
+	buf.Reset()
+	MustInit(WithWriter(&testWriter{buf: buf}), WithLevel("INFO"), WithVModule("*_test=ERROR"))
+	slog.Warn("warn from test file")
+	slog.Error("error from test file")
+	if strings.Contains(buf.String(), "warn from test file") {
+		t.Error("vmodule rule should raise level for matching file")
+	}
+	if !strings.Contains(buf.String(), "error from test file") {
+		t.Error("records at rule level should pass")
+	}
+}
+
+func TestWithVModule_Package(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}), WithLevel("ERROR"), WithVModule("wangsendi/s_log=DEBUG"))
+	slog.Info("info from package")
+	if !strings.Contains(buf.String(), "info from package") {
+		t.Error("vmodule rule should match caller package")
+	}
+}
+
+func TestWithVModule_NamedOverride(t *testing.T) {
+	defer func() { _ = Close() }()
+	defer func() { _ = SetLoggerLevels("") }()
+
+	buf := &bytes.Buffer{}
+	MustInit(WithWriter(&testWriter{buf: buf}), WithLevel("INFO"), WithVModule("vendor/*=ERROR"), WithLoggerLevels("db=DEBUG"))
+	Named("db").Debug("debug from named")
+	if !strings.Contains(buf.String(), "debug from named") {
+		t.Error("named override should still apply for unmatched files")
+	}
+}
+
+func TestParseVModule(t *testing.T) {
+	vm, err := parseVModule("internal/cache/*=DEBUG, vendor/*=error")
+	if err != nil {
+		t.Fatalf("parseVModule() failed: %v", err)
+	}
+	if len(vm.rules) != 2 || vm.minLevel != slog.LevelDebug {
+		t.Errorf("unexpected rules: %+v", vm.rules)
+	}
+
+	if _, err := parseVModule("noequals,x=LOUD,[=INFO"); err == nil {
+		t.Error("invalid spec should return error")
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"internal/cache/*", "/src/app/internal/cache/lru", true},
+		{"internal/cache/*", "/src/app/internal/store/lru", false},
+		{"vendor/*", "/src/app/vendor/github.com/x/y", true},
+		{"lru", "/src/app/internal/cache/lru", true},
+		{"cache", "/src/app/internal/cachex/lru", false},
+		{"*", "", false},
+	}
+	for _, tt := range tests {
+		if got := matchSegments(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
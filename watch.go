@@ -0,0 +1,68 @@
+package s_log
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+func Watch(path string, interval time.Duration, opts ...Option) (stop func(), err error) {
+	if interval <= 0 {
+		return nil, errors.New("s_log: watch interval must be positive")
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := reloadConfig(path, opts); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		mod, size := fi.ModTime(), fi.Size()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			fi, err := os.Stat(path)
+			if err != nil || (fi.ModTime().Equal(mod) && fi.Size() == size) {
+				continue
+			}
+			mod, size = fi.ModTime(), fi.Size()
+			if err := reloadConfig(path, opts); err != nil {
+				globalLogger.Error("log config reload failed", "path", path, "err", err)
+				continue
+			}
+			globalLogger.Info("log config reloaded", "path", path)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}, nil
+}
+
+func reloadConfig(path string, opts []Option) error {
+	c, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return Init(append(append([]Option{keepWriter}, opts...), c.Options()...)...)
+}
+
+// keepWriter marks the writer from the base options as shared by every
+// reload, so switching to config sinks and back does not close it.
+func keepWriter(c *config) { c.keepWriter = true }
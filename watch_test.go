@@ -0,0 +1,193 @@
+package s_log
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	logFile := filepath.ToSlash(filepath.Join(dir, "app.log"))
+	if err := os.WriteFile(path, []byte(`{"level":"INFO"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &lockedBuffer{}
+	stop, err := Watch(path, 10*time.Millisecond, WithWriter(buf))
+	if err != nil {
+		t.Fatalf("Watch() failed: %v", err)
+	}
+	defer stop()
+	if levelVar.Level() != slog.LevelInfo {
+		t.Fatalf("expected INFO, got %v", levelVar.Level())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"level":"DEBUG","format":"json","file":"`+logFile+`"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return levelVar.Level() == slog.LevelDebug })
+	slog.Debug("after reload")
+	content, _ := os.ReadFile(logFile)
+	if !strings.Contains(string(content), `"msg":"after reload"`) {
+		t.Errorf("reloaded sink should receive records: %s", content)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"level":"LOUD"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool {
+		content, _ := os.ReadFile(logFile)
+		return strings.Contains(string(content), "log config reload failed")
+	})
+	if levelVar.Level() != slog.LevelDebug {
+		t.Errorf("invalid config should keep previous level, got %v", levelVar.Level())
+	}
+
+	stop()
+	stop()
+}
+
+func TestWatch_RemovedLoggerLevels(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	path := filepath.Join(t.TempDir(), "log.json")
+	if err := os.WriteFile(path, []byte(`{"loggers":"db=DEBUG"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stop, err := Watch(path, 10*time.Millisecond, WithWriter(&lockedBuffer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	if LoggerLevels()["db"] != "DEBUG" {
+		t.Fatalf("expected db=DEBUG, got %v", LoggerLevels())
+	}
+
+	if err := os.WriteFile(path, []byte(`{"level":"WARN"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return levelVar.Level() == slog.LevelWarn })
+	if levels := LoggerLevels(); len(levels) != 0 {
+		t.Errorf("overrides removed from the config should be cleared, got %v", levels)
+	}
+}
+
+func TestWatch_MissingFile(t *testing.T) {
+	if _, err := Watch(filepath.Join(t.TempDir(), "missing.json"), time.Second); err == nil {
+		t.Error("Watch() should fail for missing file")
+	}
+}
+
+func TestWatch_InvalidInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []time.Duration{0, -time.Second} {
+		if stop, err := Watch(path, d); err == nil {
+			stop()
+			t.Errorf("Watch(%v) should fail", d)
+		}
+	}
+}
+
+func TestInit_RetiresOldWriter(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	first := &closeCounter{}
+	MustInit(WithWriter(first))
+	slog.Info("first")
+
+	second := &closeCounter{}
+	MustInit(WithWriter(second))
+	if first.closed != 1 {
+		t.Errorf("old writer should be closed once, got %d", first.closed)
+	}
+
+	MustInit(WithWriter(second))
+	if second.closed != 0 {
+		t.Error("writer reused by the new configuration should not be closed")
+	}
+}
+
+func openFiles(t *testing.T) int {
+	t.Helper()
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("open file descriptors are not observable on this platform")
+	}
+	return len(fds)
+}
+
+func TestInit_ClosesRetiredFileSink(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	c := Config{Format: "json", File: filepath.Join(t.TempDir(), "app.log")}
+	before := openFiles(t)
+	for i := 0; i < 5; i++ {
+		if err := Init(c.Options()...); err != nil {
+			t.Fatal(err)
+		}
+		slog.Info("reloaded", "n", i)
+	}
+	if n := openFiles(t); n > before+1 {
+		t.Errorf("retired file sinks should be closed: %d fds before, %d after", before, n)
+	}
+	_ = Close()
+	if n := openFiles(t); n > before {
+		t.Errorf("Close should close the file sink: %d fds before, %d after", before, n)
+	}
+}
+
+func TestWatch_KeepsBaseWriter(t *testing.T) {
+	defer func() { _ = Close() }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "log.json")
+	logFile := filepath.ToSlash(filepath.Join(dir, "app.log"))
+	base := &closeCounter{}
+	opts := []Option{WithWriter(base)}
+	for _, cfg := range []string{`{}`, `{"sinks":[{"type":"file","path":"` + logFile + `"}]}`, `{"level":"WARN"}`} {
+		if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := reloadConfig(path, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if base.closed != 0 {
+		t.Errorf("reloads should not close the writer passed to Watch, closed %d times", base.closed)
+	}
+	slog.Warn("back to base")
+	if !strings.Contains(base.String(), "back to base") {
+		t.Errorf("base writer should receive records again: %q", base.String())
+	}
+}
+
+type closeCounter struct {
+	lockedBuffer
+	closed int
+}
+
+func (w *closeCounter) Close() error {
+	w.closed++
+	return nil
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
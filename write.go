@@ -1,10 +1,12 @@
 package s_log
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"sync"
-	"sync/atomic"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -14,24 +16,106 @@ type Writer interface {
 	io.Closer
 }
 
+type validator interface {
+	validate() error
+}
+
 var stdoutInstance Writer = &stdoutWriter{}
 
 type stdoutWriter struct{}
 
 func (w *stdoutWriter) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
 func (w *stdoutWriter) Close() error                { return nil }
+func (w *stdoutWriter) file() *os.File              { return os.Stdout }
 
 func Stdout() Writer { return stdoutInstance }
 
-type fileWriter struct{ *lumberjack.Logger }
+var stderrInstance Writer = &stderrWriter{}
+
+type stderrWriter struct{}
+
+func (w *stderrWriter) Write(p []byte) (int, error) { return os.Stderr.Write(p) }
+func (w *stderrWriter) Close() error                { return nil }
+func (w *stderrWriter) file() *os.File              { return os.Stderr }
+
+func Stderr() Writer { return stderrInstance }
+
+type fileWriter struct {
+	*lumberjack.Logger
+	opts fileOptions
+}
+
+func (w *fileWriter) Close() error { return w.Logger.Close() }
 
-func (w *fileWriter) Close() error { return nil }
+func (w *fileWriter) validate() error {
+	if w.Filename == "" {
+		return errors.New("s_log: empty file path")
+	}
+	if err := w.opts.validate(); err != nil {
+		return fmt.Errorf("s_log: file %s: %w", w.Filename, err)
+	}
+	if err := checkWritable(w.Filename); err != nil {
+		return fmt.Errorf("s_log: file %s is not writable: %w", w.Filename, err)
+	}
+	return nil
+}
+
+// checkWritable reports whether path can be opened for appending, without
+// creating it or any missing parent directory.
+func checkWritable(path string) error {
+	if fi, err := os.Stat(path); err == nil {
+		if fi.IsDir() {
+			return errors.New("is a directory")
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	}
+	dir := filepath.Dir(path)
+	for {
+		fi, err := os.Stat(dir)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) || filepath.Dir(dir) == dir {
+			return err
+		}
+		dir = filepath.Dir(dir)
+	}
+	// Creating a temporary file is the portable way to test for write
+	// permission; it is removed right away.
+	f, err := os.CreateTemp(dir, ".s_log-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
 
 type fileOptions struct {
 	maxSize, maxBackups, maxAge int
 	compress                    bool
 }
 
+func (o fileOptions) validate() error {
+	var errs []error
+	if o.maxSize <= 0 {
+		errs = append(errs, fmt.Errorf("invalid rotation size %dMB", o.maxSize))
+	}
+	if o.maxBackups < 0 {
+		errs = append(errs, fmt.Errorf("invalid max backups %d", o.maxBackups))
+	}
+	if o.maxAge < 0 {
+		errs = append(errs, fmt.Errorf("invalid max age %d", o.maxAge))
+	}
+	return errors.Join(errs...)
+}
+
 type FileOption func(*fileOptions)
 
 func WithRotation(maxSize, maxBackups int) FileOption {
@@ -59,45 +143,92 @@ func File(path string, opts ...FileOption) Writer {
 			MaxAge:     o.maxAge,
 			Compress:   o.compress,
 		},
+		opts: *o,
 	}
 }
 
 type asyncWriter struct {
-	w      Writer
-	ch     chan []byte
-	wg     sync.WaitGroup
-	closed int32
+	w  Writer
+	ch chan asyncMsg
+	wg sync.WaitGroup
+	// mu keeps Write and Flush from sending on ch after Close closed it.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// asyncMsg is either a record to write or, when done is set, a flush marker.
+type asyncMsg struct {
+	buf  []byte
+	done chan struct{}
 }
 
 func (w *asyncWriter) Write(p []byte) (int, error) {
-	if atomic.LoadInt32(&w.closed) != 0 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
 		return len(p), nil
 	}
 	buf := make([]byte, len(p))
 	copy(buf, p)
 	select {
-	case w.ch <- buf:
+	case w.ch <- asyncMsg{buf: buf}:
 	default:
 	}
 	return len(p), nil
 }
 
+// Flush blocks until every record queued before the call is written.
+func (w *asyncWriter) Flush() error {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+	done := make(chan struct{})
+	w.ch <- asyncMsg{done: done}
+	w.mu.RUnlock()
+	<-done
+	return flushWriter(w.w)
+}
+
 func (w *asyncWriter) Close() error {
-	if !atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
 		return nil
 	}
+	w.closed = true
 	close(w.ch)
+	w.mu.Unlock()
 	w.wg.Wait()
 	return w.w.Close()
 }
 
+func (w *asyncWriter) file() *os.File {
+	if fb, ok := w.w.(fileBacked); ok {
+		return fb.file()
+	}
+	return nil
+}
+
+func (w *asyncWriter) validate() error {
+	if v, ok := w.w.(validator); ok {
+		return v.validate()
+	}
+	return nil
+}
+
 func Async(w Writer, bufferSize int) Writer {
-	aw := &asyncWriter{w: w, ch: make(chan []byte, bufferSize)}
+	aw := &asyncWriter{w: w, ch: make(chan asyncMsg, bufferSize)}
 	aw.wg.Add(1)
 	go func() {
 		defer aw.wg.Done()
-		for buf := range aw.ch {
-			_, _ = aw.w.Write(buf)
+		for m := range aw.ch {
+			if m.done != nil {
+				close(m.done)
+				continue
+			}
+			_, _ = aw.w.Write(m.buf)
 		}
 	}()
 	return aw
@@ -122,6 +253,40 @@ func (w *multiWriter) Close() error {
 	return firstErr
 }
 
+func (w *multiWriter) Flush() error {
+	var firstErr error
+	for _, writer := range w.writers {
+		if err := flushWriter(writer); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (w *multiWriter) validate() error {
+	var errs []error
+	for _, writer := range w.writers {
+		if writer == nil {
+			errs = append(errs, errors.New("s_log: nil writer in Multi"))
+		} else if v, ok := writer.(validator); ok {
+			errs = append(errs, v.validate())
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func Multi(writers ...Writer) Writer {
 	return &multiWriter{writers: writers}
 }
+
+type flusher interface {
+	Flush() error
+}
+
+// flushWriter writes out anything w still buffers, if it buffers at all.
+func flushWriter(w Writer) error {
+	if f, ok := w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
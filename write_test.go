@@ -165,10 +165,9 @@ func TestAsync(t *testing.T) {
 }
 
 func TestAsync_Concurrent(t *testing.T) {
-	buf := &bytes.Buffer{}
-	baseWriter := &testWriter{buf: buf}
+	buf := &lockedBuffer{}
 
-	w := Async(baseWriter, 100)
+	w := Async(buf, 100)
 	defer func() { _ = w.Close() }()
 
 	var wg sync.WaitGroup
@@ -184,7 +183,7 @@ func TestAsync_Concurrent(t *testing.T) {
 	wg.Wait()
 	time.Sleep(200 * time.Millisecond)
 
-	if buf.Len() == 0 {
+	if buf.String() == "" {
 		t.Error("async writer should handle concurrent writes")
 	}
 }
@@ -223,6 +222,42 @@ func TestAsync_WriteAfterClose(t *testing.T) {
 	}
 }
 
+func TestAsync_Flush(t *testing.T) {
+	buf := &lockedBuffer{}
+	w := Async(buf, 64)
+	for i := 0; i < 10; i++ {
+		_, _ = w.Write([]byte("line\n"))
+	}
+	if err := w.(flusher).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "line"); n != 10 {
+		t.Errorf("Flush should wait for queued writes, got %d of 10", n)
+	}
+	_ = w.Close()
+	if err := w.(flusher).Flush(); err != nil {
+		t.Errorf("Flush after Close should be a no-op, got %v", err)
+	}
+}
+
+func TestAsync_WriteDuringClose(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		w := Async(&lockedBuffer{}, 1)
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 100; k++ {
+					_, _ = w.Write([]byte("x"))
+				}
+			}()
+		}
+		_ = w.Close()
+		wg.Wait()
+	}
+}
+
 func TestMulti(t *testing.T) {
 	buf1 := &bytes.Buffer{}
 	buf2 := &bytes.Buffer{}